@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	sd "github.com/michaelhugi/go-hugipipes-signal-drawer"
+)
+
+//series is one named column of values
+type series struct {
+	Name   string    `json:"name"`
+	Values []float64 `json:"values"`
+}
+
+//table is data read from a CSV or JSON file. X contains the times in seconds for wave plots or the frequencies in Hz
+//for spectrum plots, every series one value per x
+type table struct {
+	X      []float64 `json:"x"`
+	Series []series  `json:"series"`
+}
+
+//input is the decoded input file, either a recording or a table
+type input struct {
+	wav   *sd.Wav
+	table *table
+}
+
+//readInput reads a WAV, CSV or JSON file chosen by the extension of path
+func readInput(path string) (*input, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav", ".wave":
+		wav, err := sd.LoadWav(path)
+		if err != nil {
+			return nil, err
+		}
+		return &input{wav: wav}, nil
+	case ".csv":
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		t, err := readCSV(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return &input{table: t}, nil
+	case ".json":
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		t := &table{}
+		if err := json.NewDecoder(f).Decode(t); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return &input{table: t}, nil
+	}
+	return nil, fmt.Errorf("%s: %w", path, sd.ErrUnsupportedFormat)
+}
+
+//readCSV reads a table with the x values in the first column and one series per further column. If the first row is
+//not numeric, it is used as the names of the series
+func readCSV(r io.Reader) (*table, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	t := &table{}
+	for row, record := range records {
+		if len(record) < 2 {
+			return nil, fmt.Errorf("row %d: expected at least 2 columns", row+1)
+		}
+		if t.Series == nil {
+			t.Series = make([]series, len(record)-1)
+			if _, err := strconv.ParseFloat(strings.TrimSpace(record[0]), 64); err != nil {
+				for i := range t.Series {
+					t.Series[i].Name = strings.TrimSpace(record[i+1])
+				}
+				continue
+			}
+		}
+		if len(record)-1 != len(t.Series) {
+			return nil, fmt.Errorf("row %d: expected %d columns", row+1, len(t.Series)+1)
+		}
+		values := make([]float64, len(record))
+		for i, field := range record {
+			v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", row+1, err)
+			}
+			values[i] = v
+		}
+		t.X = append(t.X, values[0])
+		for i := range t.Series {
+			t.Series[i].Values = append(t.Series[i].Values, values[i+1])
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	tab, err := readCSV(strings.NewReader("time,left,right\n0,0.5,-0.5\n0.001,1,-1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tab.X) != 2 || tab.X[1] != 0.001 {
+		t.Errorf("x = %v", tab.X)
+	}
+	if len(tab.Series) != 2 || tab.Series[0].Name != "left" || tab.Series[1].Values[1] != -1 {
+		t.Errorf("series = %+v", tab.Series)
+	}
+
+	tab, err = readCSV(strings.NewReader("100,1\n200,2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tab.X) != 2 || tab.Series[0].Name != "" {
+		t.Errorf("headless table = %+v", tab)
+	}
+
+	if _, err := readCSV(strings.NewReader("1,2\n3,x\n")); err == nil {
+		t.Error("expected an error for a non-numeric value")
+	}
+	if _, err := readCSV(strings.NewReader("1,2\n3\n")); err == nil {
+		t.Error("expected an error for a missing column")
+	}
+}
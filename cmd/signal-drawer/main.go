@@ -0,0 +1,264 @@
+//Command signal-drawer draws a wave, spectrum or spectrogram of a WAV, CSV or JSON file to a PNG, JPEG or SVG image.
+//
+//Usage:
+//
+//	signal-drawer -in recording.wav -type spectrum -freq-scale log2 -db -out spectrum.svg
+//
+//CSV files contain the x values in the first column and one series per further column, optionally with a header
+//row of names. JSON files contain {"x":[...],"series":[{"name":"...","values":[...]}]}. The x values are times in
+//seconds for wave plots and frequencies in Hz for spectrum plots. Spectrograms can only be drawn from WAV files.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	mn "github.com/michaelhugi/go-hugipipes-musical-notes"
+	sd "github.com/michaelhugi/go-hugipipes-signal-drawer"
+)
+
+//options are the parsed command line flags
+type options struct {
+	in        string
+	out       string
+	widget    string
+	title     string
+	width     int
+	height    int
+	startFreq float64
+	endFreq   float64
+	freqScale string
+	startTime time.Duration
+	endTime   time.Duration
+	a4        float64
+	window    string
+	segment   int
+	decibel   bool
+	font      string
+	fontSize  float64
+	theme     string
+	colormap  string
+	colorbar  bool
+}
+
+func main() {
+	o := options{}
+	flag.StringVar(&o.in, "in", "", "input file (.wav, .csv or .json)")
+	flag.StringVar(&o.out, "out", "plot.png", "output file (.png, .jpg, .jpeg or .svg)")
+	flag.StringVar(&o.widget, "type", "wave", "plot type: wave, spectrum or spectrogram")
+	flag.StringVar(&o.title, "title", "", "plot title, defaults to the input file name")
+	flag.IntVar(&o.width, "width", 2000, "plot width in pixels without labels")
+	flag.IntVar(&o.height, "height", 300, "plot height in pixels without labels")
+	flag.Float64Var(&o.startFreq, "start-freq", 20, "lowest frequency in Hz (spectrum and spectrogram)")
+	flag.Float64Var(&o.endFreq, "end-freq", 20000, "highest frequency in Hz (spectrum and spectrogram)")
+	flag.StringVar(&o.freqScale, "freq-scale", "linear", "frequency axis: linear, log2 or log10")
+	flag.DurationVar(&o.startTime, "start-time", 0, "start of the time range (wave and spectrogram)")
+	flag.DurationVar(&o.endTime, "end-time", 0, "end of the time range, 0 for the whole signal (wave and spectrogram)")
+	flag.Float64Var(&o.a4, "a4", 440, "frequency of A4 in Hz for the equal temperament of the note axis")
+	flag.StringVar(&o.window, "window", "hann", "FFT window: rectangular, hann, blackman-harris or flat-top")
+	flag.IntVar(&o.segment, "segment", 0, "FFT segment size in samples, 0 for the default")
+	flag.BoolVar(&o.decibel, "db", false, "show magnitudes in decibels")
+	flag.StringVar(&o.font, "font", "", "TrueType or OpenType font file, defaults to Go Regular")
+	flag.Float64Var(&o.fontSize, "font-size", 12, "size in pixels of the tick labels, titles and axis labels are scaled along")
+	flag.StringVar(&o.theme, "theme", "dark", "colors: dark, light, print or high-contrast")
+	flag.StringVar(&o.colormap, "colormap", "", "spectrogram colors: viridis, magma, inferno, grayscale, diverging or heat, defaults to the theme")
+	flag.BoolVar(&o.colorbar, "colorbar", false, "draw a colorbar right of the spectrogram")
+	flag.Parse()
+
+	if err := run(o); err != nil {
+		fmt.Fprintln(os.Stderr, "signal-drawer:", err)
+		os.Exit(1)
+	}
+}
+
+//run draws the plot described by o
+func run(o options) error {
+	if o.in == "" {
+		return errors.New("no input file, set -in")
+	}
+	if o.startFreq >= o.endFreq {
+		return fmt.Errorf("-start-freq %g is not below -end-freq %g", o.startFreq, o.endFreq)
+	}
+	if o.title == "" {
+		o.title = o.in
+	}
+	in, err := readInput(o.in)
+	if err != nil {
+		return err
+	}
+	freqScale, err := parseFreqScale(o.freqScale)
+	if err != nil {
+		return err
+	}
+	window, err := parseWindow(o.window)
+	if err != nil {
+		return err
+	}
+	analysis := sd.NewSpectrumAnalysis().Window(window).SegmentSize(o.segment)
+	temp := mn.NewMTemperamentEqual(o.a4)
+
+	theme, err := parseTheme(o.theme)
+	if err != nil {
+		return err
+	}
+	drawer := sd.NewDrawer().PlotWidth(o.width).PlotHeight(o.height).Theme(theme)
+	if o.font != "" {
+		font, err := sd.LoadFont(o.font)
+		if err != nil {
+			return err
+		}
+		drawer.Font(font)
+	}
+	drawer.FontSizes(o.fontSize*4/3, o.fontSize*13/12, o.fontSize)
+	switch o.widget {
+	case "wave":
+		w, err := newWave(drawer, in, o.title)
+		if err != nil {
+			return err
+		}
+		if o.endTime > 0 {
+			w.EndTime(o.endTime)
+		}
+		w.StartTime(o.startTime)
+		drawer.AddPlot(w)
+	case "spectrum":
+		s, err := newSpectrum(drawer, in, analysis, o.title)
+		if err != nil {
+			return err
+		}
+		s.Temperament(temp).FreqScale(freqScale).EndFreq(o.endFreq).StartFreq(o.startFreq).EndFreq(o.endFreq)
+		if o.decibel {
+			s.YScale(sd.YScaleDecibel)
+		}
+		drawer.AddPlot(s)
+	case "spectrogram":
+		if in.wav == nil {
+			return errors.New("spectrograms can only be drawn from WAV files")
+		}
+		s, err := sd.NewSpectrogramDrawerFromSamples(drawer, in.wav.Channels()[0], float64(in.wav.SampleRate()), analysis, o.title)
+		if err != nil {
+			return err
+		}
+		s.Temperament(temp).FreqScale(freqScale).EndFreq(o.endFreq).StartFreq(o.startFreq).EndFreq(o.endFreq)
+		if o.endTime > 0 {
+			s.EndTime(o.endTime)
+		}
+		s.StartTime(o.startTime)
+		if o.decibel {
+			s.MagnitudeScale(sd.YScaleDecibel)
+		}
+		if o.colormap != "" {
+			colormap, err := parseColormap(o.colormap)
+			if err != nil {
+				return err
+			}
+			s.ColorMap(colormap.At)
+		}
+		if o.colorbar {
+			drawer.AddRow(s, sd.NewSpectrogramColorbar(drawer, s, ""))
+		} else {
+			drawer.AddPlot(s)
+		}
+	default:
+		return fmt.Errorf("unknown plot type %q", o.widget)
+	}
+
+	d, err := drawer.Build()
+	if err != nil {
+		return err
+	}
+	return d.SaveFile(o.out)
+}
+
+//newWave creates a WaveDrawer with all channels or series of in
+func newWave(drawer *sd.DrawerBuilder, in *input, title string) (*sd.WaveDrawer, error) {
+	if in.wav != nil {
+		return sd.NewWaveDrawerFromWav(drawer, in.wav, title), nil
+	}
+	times := make([]time.Duration, len(in.table.X))
+	for i, x := range in.table.X {
+		times[i] = time.Duration(x * float64(time.Second))
+	}
+	w := sd.NewWaveDrawer(drawer, times, title)
+	for _, s := range in.table.Series {
+		w.SetItems(sd.NewWaveDrawerItems(s.Values, nil).Style(sd.WaveDrawStyleLines).Name(s.Name))
+	}
+	return w, nil
+}
+
+//newSpectrum creates a SpectrumDrawer with the spectra of all channels or the series of in
+func newSpectrum(drawer *sd.DrawerBuilder, in *input, analysis *sd.SpectrumAnalysis, title string) (*sd.SpectrumDrawer, error) {
+	if in.wav != nil {
+		return sd.NewSpectrumDrawerFromWav(drawer, in.wav, analysis, title)
+	}
+	s := sd.NewSpectrumDrawer(drawer, in.table.X, title)
+	for _, series := range in.table.Series {
+		s.SetItems(sd.NewSpectrumDrawerItems(series.Values, true, nil).Name(series.Name))
+	}
+	return s, nil
+}
+
+//parseFreqScale returns the FrequencyScale named name
+func parseFreqScale(name string) (sd.FrequencyScale, error) {
+	switch name {
+	case "linear":
+		return sd.FrequencyScaleLinear, nil
+	case "log2":
+		return sd.FrequencyScaleLog2, nil
+	case "log10":
+		return sd.FrequencyScaleLog10, nil
+	}
+	return sd.FrequencyScaleLinear, fmt.Errorf("unknown frequency scale %q", name)
+}
+
+//parseTheme returns the Theme named name
+func parseTheme(name string) (sd.Theme, error) {
+	switch name {
+	case "dark":
+		return sd.DarkTheme(), nil
+	case "light":
+		return sd.LightTheme(), nil
+	case "print":
+		return sd.PrintTheme(), nil
+	case "high-contrast":
+		return sd.HighContrastTheme(), nil
+	}
+	return sd.DarkTheme(), fmt.Errorf("unknown theme %q", name)
+}
+
+//parseColormap returns the Colormap named name
+func parseColormap(name string) (sd.Colormap, error) {
+	switch name {
+	case "viridis":
+		return sd.ViridisColormap(), nil
+	case "magma":
+		return sd.MagmaColormap(), nil
+	case "inferno":
+		return sd.InfernoColormap(), nil
+	case "grayscale":
+		return sd.GrayscaleColormap(), nil
+	case "diverging":
+		return sd.DivergingColormap(), nil
+	case "heat":
+		return sd.HeatColormap(), nil
+	}
+	return sd.HeatColormap(), fmt.Errorf("unknown colormap %q", name)
+}
+
+//parseWindow returns the Window named name
+func parseWindow(name string) (sd.Window, error) {
+	switch name {
+	case "rectangular":
+		return sd.WindowRectangular, nil
+	case "hann":
+		return sd.WindowHann, nil
+	case "blackman-harris":
+		return sd.WindowBlackmanHarris, nil
+	case "flat-top":
+		return sd.WindowFlatTop, nil
+	}
+	return sd.WindowHann, fmt.Errorf("unknown window %q", name)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunFrequencyRange(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "spectrum.csv")
+	if err := os.WriteFile(in, []byte("20000,0.1\n25000,0.5\n30000,1\n35000,0.2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	o := options{
+		in:        in,
+		out:       filepath.Join(dir, "spectrum.svg"),
+		widget:    "spectrum",
+		width:     400,
+		height:    100,
+		startFreq: 25000,
+		endFreq:   30000,
+		freqScale: "linear",
+		a4:        440,
+		window:    "hann",
+		fontSize:  12,
+		theme:     "dark",
+	}
+	//Both frequencies are above the default end frequency of 20kHz
+	if err := run(o); err != nil {
+		t.Fatal(err)
+	}
+	svg, err := os.ReadFile(o.out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, label := range []string{"25000.000000Hz", "30000.000000Hz"} {
+		if !strings.Contains(string(svg), label) {
+			t.Errorf("expected the frequency label %s in the plot", label)
+		}
+	}
+	if strings.Contains(string(svg), ">20.000000Hz<") {
+		t.Error("expected the default start frequency to be replaced")
+	}
+
+	o.startFreq, o.endFreq = 30000, 25000
+	if err := run(o); err == nil || !strings.Contains(err.Error(), "-start-freq") {
+		t.Errorf("expected an error for a start frequency above the end frequency, got %v", err)
+	}
+}
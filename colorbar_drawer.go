@@ -0,0 +1,272 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"context"
+	"image"
+	"image/color"
+)
+
+//defaultColorbarWidth is the width in pixels of the color gradient of a ColorbarDrawer if no width is set
+const defaultColorbarWidth = 20
+
+//ColorbarDrawer is a widget that shows the colors of a color map with the values they stand for. Place it in the
+//cell beside the widget it belongs to, like with DrawerBuilder.AddRow
+type ColorbarDrawer struct {
+	*DrawerBuilder
+	size        widgetSize
+	cache       *colorbarDrawerCache
+	title       string
+	colors      widgetTheme
+	valueRange  yRange
+	spectrogram *SpectrogramDrawer
+	yUnit       string
+	yFormatter  func(value float64) string
+}
+
+//NewColorbarDrawer is the constructor for ColorbarDrawer showing the color map of the theme for values from min to
+//max. The gradient is 20 pixels wide with room for the labels on the right
+func NewColorbarDrawer(drawer *DrawerBuilder, min float64, max float64, title string) *ColorbarDrawer {
+	s := &ColorbarDrawer{
+		DrawerBuilder: drawer,
+		title:         title,
+		valueRange:    newYRange(min, max),
+	}
+	s.size.width = defaultColorbarWidth
+	return s
+}
+
+//NewSpectrogramColorbar is a constructor for ColorbarDrawer showing the color map and magnitude range of spectrogram.
+//Changes of the spectrogram are followed until it is drawn
+func NewSpectrogramColorbar(drawer *DrawerBuilder, spectrogram *SpectrogramDrawer, title string) *ColorbarDrawer {
+	s := NewColorbarDrawer(drawer, 0, 1, title)
+	s.spectrogram = spectrogram
+	return s
+}
+
+//colorbarDrawerCache contains data that is recalculated often during drawing
+type colorbarDrawerCache struct {
+	x                int
+	theme            Theme
+	canvas           VectorDrawable
+	margins          margins
+	plotWidth        int
+	plotHeight       int
+	valueRange       yRange
+	calculatedWidth  int
+	calculatedHeight int
+}
+
+//ColorMap sets the function mapping a value, normalized to [0,1], to its color, like ViridisColormap().At. It is
+//ignored if the colorbar belongs to a spectrogram. Default is the color map of the theme
+func (s *ColorbarDrawer) ColorMap(colorMap func(v float64) color.Color) *ColorbarDrawer {
+	s.colors.colorMap = colorMap
+	return s
+}
+
+//Theme sets the colors of this plot, replacing the theme of the DrawerBuilder. Colors set with the color setters
+//still override the theme
+func (s *ColorbarDrawer) Theme(theme Theme) *ColorbarDrawer {
+	s.colors.theme = &theme
+	return s
+}
+
+//BackgroundColor sets the background-color of the plot. Default is the background of the theme
+func (s *ColorbarDrawer) BackgroundColor(backgroundColor color.Color) *ColorbarDrawer {
+	s.colors.background = backgroundColor
+	return s
+}
+
+//DividerColor sets the divider-color of the plot. Default is the divider color of the theme
+func (s *ColorbarDrawer) DividerColor(dividerColor color.Color) *ColorbarDrawer {
+	s.colors.divider = dividerColor
+	return s
+}
+
+//AxisColor sets the color of the border and the labels. Default is the axis color of the theme
+func (s *ColorbarDrawer) AxisColor(axisColor color.Color) *ColorbarDrawer {
+	s.colors.axis = axisColor
+	return s
+}
+
+//TitleColor sets the color of the title. Default is the title color of the theme
+func (s *ColorbarDrawer) TitleColor(titleColor color.Color) *ColorbarDrawer {
+	s.colors.title = titleColor
+	return s
+}
+
+//Width sets the width of the gradient without margins. Default is 20
+func (s *ColorbarDrawer) Width(width int) *ColorbarDrawer {
+	s.size.width = max(width, 0)
+	return s
+}
+
+//Height sets the height of the gradient without margins. Default is the plot height of the DrawerBuilder
+func (s *ColorbarDrawer) Height(height int) *ColorbarDrawer {
+	s.size.height = max(height, 0)
+	return s
+}
+
+//Margins sets the space around the gradient for the title and the labels. Default is an eighth of the label space of
+//the DrawerBuilder on the left and the space fitting the title and the labels on all other sides, but at least the
+//label space
+func (s *ColorbarDrawer) Margins(top int, right int, bottom int, left int) *ColorbarDrawer {
+	s.size.setMargins(top, right, bottom, left)
+	return s
+}
+
+//YUnit sets the unit appended to the value labels. Default is no unit or dB for spectrograms with YScaleDecibel
+func (s *ColorbarDrawer) YUnit(yUnit string) *ColorbarDrawer {
+	s.yUnit = yUnit
+	return s
+}
+
+//YFormatter sets a function formatting the value labels. It replaces the default formatting with YUnit
+func (s *ColorbarDrawer) YFormatter(yFormatter func(value float64) string) *ColorbarDrawer {
+	s.yFormatter = yFormatter
+	return s
+}
+
+//newColorbarDrawerCache creates a new cache with pre-calculated values for plotting to the region bounds
+func (s *ColorbarDrawer) newColorbarDrawerCache(bounds image.Rectangle) *colorbarDrawerCache {
+	m := s.plotMargins()
+	c := &colorbarDrawerCache{
+		x:                bounds.Min.X,
+		theme:            s.colors.resolve(s.DrawerBuilder),
+		margins:          m,
+		plotWidth:        bounds.Dx() - m.left - m.right,
+		plotHeight:       bounds.Dy() - m.top - m.bottom,
+		valueRange:       s.labelRange(),
+		calculatedWidth:  bounds.Dx(),
+		calculatedHeight: bounds.Dy(),
+	}
+	if s.spectrogram != nil {
+		c.theme.ColorMap = s.spectrogram.colors.resolve(s.spectrogram.DrawerBuilder).ColorMap
+	}
+	return c
+}
+
+//labelRange returns the range of values shown by the gradient, which is the magnitude range of the spectrogram if the
+//colorbar belongs to one
+func (s *ColorbarDrawer) labelRange() yRange {
+	if s.spectrogram != nil {
+		return s.spectrogram.newMagnitudeRange()
+	}
+	return s.valueRange
+}
+
+//yAxisTicks returns the ticks right of the gradient for values in r for a gradient of the height set in the
+//DrawerBuilder or the widget
+func (s *ColorbarDrawer) yAxisTicks(r yRange) yAxisTicks {
+	unit := s.yUnit
+	if unit == "" && r.decibel {
+		unit = "dB"
+	}
+	return yAxisTicks{
+		height:      s.size.plotHeight(s.DrawerBuilder),
+		tickLength:  s.spacePart / 2,
+		style:       s.tickStyle(),
+		unit:        unit,
+		formatter:   s.yFormatter,
+		labelsRight: true,
+	}
+}
+
+//Draw implements DrawerWidget interface
+func (s *ColorbarDrawer) Draw(region image.Rectangle, canvas VectorDrawable) error {
+	return s.DrawContext(context.Background(), region, canvas)
+}
+
+//DrawContext implements ContextDrawerWidget interface
+func (s *ColorbarDrawer) DrawContext(ctx context.Context, region image.Rectangle, canvas VectorDrawable) error {
+	y := region.Min.Y
+	s.cache = s.newColorbarDrawerCache(region)
+	s.cache.canvas = canvas
+	s.drawBackground(y)
+	s.drawPlotTitle(s.title, y)
+	s.drawGradient(y)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.drawDivider(y)
+	return nil
+}
+
+//drawBackground plots the background
+func (s *ColorbarDrawer) drawBackground(y int) {
+	bottom := y + s.cache.calculatedHeight
+	s.cache.canvas.FillRect(image.Rect(s.cache.x, y, s.cache.x+s.cache.calculatedWidth, bottom), s.cache.theme.Background)
+}
+
+//drawGradient draws one line per pixel row in the color of its value, a border and the value labels
+func (s *ColorbarDrawer) drawGradient(y int) {
+	left := s.cache.x + s.cache.margins.left
+	right := left + s.cache.plotWidth
+	top := y + s.cache.margins.top
+	bottom := top + s.cache.plotHeight
+	for row := 0; row < s.cache.plotHeight; row++ {
+		c := s.cache.theme.ColorMap((float64(row) + 0.5) / float64(s.cache.plotHeight))
+		s.cache.canvas.DrawLine(left, bottom-1-row, right-1, bottom-1-row, c)
+	}
+	s.cache.canvas.DrawPolyline([]image.Point{{X: left, Y: top}, {X: right, Y: top}, {X: right, Y: bottom}, {X: left, Y: bottom}, {X: left, Y: top}}, s.cache.theme.Axis)
+
+	ticks := s.yAxisTicks(s.cache.valueRange)
+	ticks.canvas = s.cache.canvas
+	ticks.x = right
+	ticks.bottom = bottom
+	ticks.height = s.cache.plotHeight
+	ticks.color = s.cache.theme.Axis
+	ticks.draw(s.cache.valueRange)
+}
+
+//drawDivider draws a line at the top of the plot if it isn't in the first row and at the left if it isn't in the
+//first column
+func (s *ColorbarDrawer) drawDivider(y int) {
+	x := s.cache.x
+	if y > 0 {
+		s.cache.canvas.DrawLine(x, y, x+s.cache.calculatedWidth-1, y, s.cache.theme.Divider)
+	}
+	if x > 0 {
+		s.cache.canvas.DrawLine(x, y, x, y+s.cache.calculatedHeight-1, s.cache.theme.Divider)
+	}
+}
+
+//Draws the plot title above the gradient or at the top of the widget if the top margin is too small
+func (s *ColorbarDrawer) drawPlotTitle(title string, top int) {
+	style := s.axisStyle()
+	x := s.cache.x + s.cache.margins.left
+	y := max(top+s.cache.margins.top-s.spacePart-style.descent(), top+style.ascent())
+	s.cache.canvas.DrawText(x, y, title, style, s.cache.theme.Title)
+}
+
+//getTitle returns the title of the plot
+func (s *ColorbarDrawer) getTitle() string {
+	return s.title
+}
+
+//Validate checks the data of the plot. It is called before the plot is drawn
+func (s *ColorbarDrawer) Validate() error {
+	return nil
+}
+
+//Measure implements DrawerWidget interface
+func (s *ColorbarDrawer) Measure() Measurement {
+	return s.size.measure(s.DrawerBuilder, s.plotMargins())
+}
+
+//plotMargins returns the margins around the plot
+func (s *ColorbarDrawer) plotMargins() margins {
+	m := s.size.plotMargins(s.DrawerBuilder, s.textMargins())
+	if s.size.margins == nil {
+		m.left = s.spacePart
+	}
+	return m
+}
+
+//textMargins returns the margins fitting the title and the value labels
+func (s *ColorbarDrawer) textMargins() margins {
+	r := s.labelRange()
+	return margins{
+		top:   s.titleMargin(s.title, s.axisStyle()),
+		right: s.yAxisTicks(r).width(r) + s.spacePart,
+	}
+}
@@ -0,0 +1,101 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"image/color"
+	"math"
+)
+
+//Colormap maps normalized values in [0,1] to colors by interpolating linearly between evenly spaced stops. Pass At
+//to setters like SpectrogramDrawer.ColorMap or Theme.ColorMap
+type Colormap struct {
+	stops []color.RGBA
+}
+
+//NewColormap creates a Colormap from the colors at 0 over evenly spaced values to the color at 1. A single color
+//creates a constant Colormap
+func NewColormap(stops ...color.Color) Colormap {
+	s := Colormap{stops: make([]color.RGBA, len(stops))}
+	for i, c := range stops {
+		s.stops[i] = color.RGBAModel.Convert(c).(color.RGBA)
+	}
+	return s
+}
+
+//ViridisColormap returns the perceptually uniform colormap from dark blue over green to yellow, readable in grayscale
+//and by colorblind viewers
+func ViridisColormap() Colormap {
+	return hexColormap(0x440154, 0x482878, 0x3e4a89, 0x31688e, 0x26828e, 0x1f9e89, 0x35b779, 0x6dcd59, 0xb4de2c, 0xfde725)
+}
+
+//MagmaColormap returns the perceptually uniform colormap from black over purple and orange to light yellow
+func MagmaColormap() Colormap {
+	return hexColormap(0x000004, 0x180f3e, 0x451077, 0x721f81, 0x9f2f7f, 0xcd4071, 0xf1605d, 0xfd9567, 0xfec98d, 0xfcfdbf)
+}
+
+//InfernoColormap returns the perceptually uniform colormap from black over purple and red to yellow
+func InfernoColormap() Colormap {
+	return hexColormap(0x000004, 0x1b0c42, 0x4b0c6b, 0x781c6d, 0xa52c60, 0xcf4446, 0xed6925, 0xfb9a06, 0xf7d03c, 0xfcffa4)
+}
+
+//GrayscaleColormap returns the colormap from black to white
+func GrayscaleColormap() Colormap {
+	return hexColormap(0x000000, 0xffffff)
+}
+
+//DivergingColormap returns the colormap from blue over white at 0.5 to red for values with a meaningful center like
+//phases or differences
+func DivergingColormap() Colormap {
+	return hexColormap(0x2166ac, 0x67a9cf, 0xd1e5f0, 0xf7f7f7, 0xfddbc7, 0xef8a62, 0xb2182b)
+}
+
+//HeatColormap returns the colormap from black over blue, red and yellow to white. This is the default of DarkTheme
+func HeatColormap() Colormap {
+	return heatColormap
+}
+
+//heatColormap is the colormap of heatColor
+var heatColormap = hexColormap(0x000000, 0x0000a0, 0xc80050, 0xffa000, 0xffffff)
+
+//heatColor maps a value in [0,1] to a color from black over blue, red and yellow to white
+func heatColor(v float64) color.Color {
+	return heatColormap.At(v)
+}
+
+//hexColormap creates an opaque Colormap from colors like 0xff0000
+func hexColormap(stops ...uint32) Colormap {
+	s := Colormap{stops: make([]color.RGBA, len(stops))}
+	for i, c := range stops {
+		s.stops[i] = color.RGBA{A: 255, R: uint8(c >> 16), G: uint8(c >> 8), B: uint8(c)}
+	}
+	return s
+}
+
+//At returns the color of v in [0,1]. Values outside are clamped, NaN returns the color of 0
+func (s Colormap) At(v float64) color.Color {
+	if len(s.stops) == 0 {
+		return color.RGBA{}
+	}
+	if math.IsNaN(v) || v <= 0 || len(s.stops) == 1 {
+		return s.stops[0]
+	}
+	if v >= 1 {
+		return s.stops[len(s.stops)-1]
+	}
+	pos := v * float64(len(s.stops)-1)
+	i := int(pos)
+	f := pos - float64(i)
+	a, b := s.stops[i], s.stops[i+1]
+	mix := func(a uint8, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + f*(float64(b)-float64(a))))
+	}
+	return color.RGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: mix(a.A, b.A)}
+}
+
+//Reversed returns the colormap with the color of 1 at 0 and the other way round
+func (s Colormap) Reversed() Colormap {
+	r := Colormap{stops: make([]color.RGBA, len(s.stops))}
+	for i, c := range s.stops {
+		r.stops[len(s.stops)-1-i] = c
+	}
+	return r
+}
@@ -0,0 +1,78 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestColormap(t *testing.T) {
+	c := NewColormap(image.Black.C, color.RGBA{R: 200, G: 100, A: 255}, image.White.C)
+	tests := []struct {
+		v    float64
+		want color.RGBA
+	}{
+		{v: -1, want: color.RGBA{A: 255}},
+		{v: math.NaN(), want: color.RGBA{A: 255}},
+		{v: 0.25, want: color.RGBA{R: 100, G: 50, A: 255}},
+		{v: 0.5, want: color.RGBA{R: 200, G: 100, A: 255}},
+		{v: 0.75, want: color.RGBA{R: 228, G: 178, B: 128, A: 255}},
+		{v: 2, want: color.RGBA{R: 255, G: 255, B: 255, A: 255}},
+	}
+	for _, test := range tests {
+		if got := c.At(test.v); got != test.want {
+			t.Errorf("At(%f) = %v, want %v", test.v, got, test.want)
+		}
+	}
+
+	r := c.Reversed()
+	for _, v := range []float64{0, 0.3, 0.5, 1} {
+		if r.At(v) != c.At(1-v) {
+			t.Errorf("reversed At(%f) = %v, want %v", v, r.At(v), c.At(1-v))
+		}
+	}
+	if c.At(0) != (color.RGBA{A: 255}) {
+		t.Error("reversing changed the original colormap")
+	}
+
+	single := NewColormap(color.RGBA{R: 10, G: 20, B: 30, A: 255})
+	for _, v := range []float64{0, 0.5, 1} {
+		if got := single.At(v); got != (color.RGBA{R: 10, G: 20, B: 30, A: 255}) {
+			t.Errorf("expected a constant colormap with one stop, got %v at %f", got, v)
+		}
+	}
+
+	for _, preset := range []Colormap{ViridisColormap(), MagmaColormap(), InfernoColormap(), GrayscaleColormap(), DivergingColormap(), HeatColormap()} {
+		if len(preset.stops) < 2 {
+			t.Errorf("expected at least 2 stops, got %d", len(preset.stops))
+		}
+	}
+}
+
+func TestColorbarDrawer(t *testing.T) {
+	d := NewDrawer().PlotHeight(100).LabelSpace(40)
+	colorbar := NewColorbarDrawer(d, -60, 0, "dB").ColorMap(GrayscaleColormap().At)
+	d.AddPlot(colorbar)
+	if w := colorbar.Measure().Width; w != 20+40+5 {
+		t.Errorf("expected a narrow colorbar, got a width of %d", w)
+	}
+	dr, err := d.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := dr.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	//The gradient spans from x=5 to x=25 and from y=40 to y=140
+	if c := img.At(15, 41).(color.RGBA); c.R < 250 {
+		t.Errorf("expected white at the top, got %v", c)
+	}
+	if c := img.At(15, 138).(color.RGBA); c.R > 5 {
+		t.Errorf("expected black at the bottom, got %v", c)
+	}
+	if c := img.At(15, 90).(color.RGBA); c.R < 100 || c.R > 155 {
+		t.Errorf("expected gray in the middle, got %v", c)
+	}
+}
@@ -1,9 +1,33 @@
 package go_hugipipes_signal_drawer
 
-import "image/color"
+import (
+	"image/color"
+	"math"
+)
 
 var green = color.RGBA{A: 255, R: 0, G: 128, B: 0}
 var red = color.RGBA{A: 255, R: 128, G: 0, B: 0}
 var blue = color.RGBA{A: 255, R: 0, G: 0, B: 128}
 var yellow = color.RGBA{A: 255, R: 255, G: 255, B: 0}
 var gray = color.RGBA{A: 255, R: 128, G: 128, B: 128}
+
+//blend mixes fg over bg, where alpha in [0,1] is the opacity of fg
+func blend(bg color.Color, fg color.Color, alpha float64) color.Color {
+	alpha = math.Max(0, math.Min(1, alpha))
+	br, bgG, bb, ba := bg.RGBA()
+	fr, fgG, fb, fa := fg.RGBA()
+	mix := func(b uint32, f uint32) uint16 {
+		return uint16(float64(b) + alpha*(float64(f)-float64(b)))
+	}
+	return color.RGBA64{R: mix(br, fr), G: mix(bgG, fgG), B: mix(bb, fb), A: mix(ba, fa)}
+}
+
+//channelColors are used for the channels of multichannel recordings
+var channelColors = []color.Color{
+	color.RGBA{A: 255, R: 0, G: 200, B: 0},
+	color.RGBA{A: 255, R: 230, G: 40, B: 40},
+	color.RGBA{A: 255, R: 60, G: 120, B: 255},
+	yellow,
+	color.RGBA{A: 255, R: 0, G: 220, B: 220},
+	color.RGBA{A: 255, R: 220, G: 0, B: 220},
+}
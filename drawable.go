@@ -6,23 +6,69 @@ import (
 	"golang.org/x/image/math/fixed"
 	"image"
 	"image/color"
+	"image/draw"
 )
 
+//Drawable is the target all widgets are drawn to. Drawables that also implement VectorDrawable are used with their
+//native primitives, all others are drawn to pixel by pixel.
+//A Drawable is only used by one goroutine at a time unless it implements ConcurrentDrawable
 type Drawable interface {
 	Set(x, y int, c color.Color)
 	DrawString(x, y int, text string, c color.Color)
 }
 
+//ConcurrentDrawable is a Drawable that plots can be drawn to in parallel, see DrawerBuilder.Parallelism.
+//Region returns a Drawable clipped to r. Drawables returned for regions that don't overlap must be safe to use from
+//different goroutines at the same time, while the ConcurrentDrawable itself is not used until all of them are done
+type ConcurrentDrawable interface {
+	Drawable
+	Region(r image.Rectangle) Drawable
+}
+
+//drawTarget is implemented by drawables that draw to the pixels of another one, like regions and adapters.
+//Widgets compare the targets to find out if they draw to the same pixels as before through a different drawable
+type drawTarget interface {
+	drawTarget() any
+}
+
+//canvasTarget returns what drawable draws to, which is drawable itself unless it implements drawTarget
+func canvasTarget(drawable Drawable) any {
+	if t, ok := drawable.(drawTarget); ok {
+		return t.drawTarget()
+	}
+	return drawable
+}
+
+//ImageDrawable is a VectorDrawable that rasterizes into an image.RGBA
 type ImageDrawable struct {
 	img *image.RGBA
+	//root is the image the regions are taken from
+	root *image.RGBA
 }
 
+//NewImageDrawable is the constructor for ImageDrawable
 func NewImageDrawable(img *image.RGBA) *ImageDrawable {
 	return &ImageDrawable{
-		img: img,
+		img:  img,
+		root: img,
 	}
 }
 
+//Region implements ConcurrentDrawable interface. The returned ImageDrawable draws to the same pixels, so regions that
+//don't overlap can be drawn to in parallel
+func (s *ImageDrawable) Region(r image.Rectangle) Drawable {
+	return &ImageDrawable{
+		img:  s.img.SubImage(r).(*image.RGBA),
+		root: s.root,
+	}
+}
+
+//drawTarget implements drawTarget interface. All regions of an image draw to the image
+func (s *ImageDrawable) drawTarget() any {
+	return s.root
+}
+
+//DrawString implements Drawable interface
 func (s *ImageDrawable) DrawString(x, y int, text string, c color.Color) {
 	point := fixed.Point26_6{X: fixed.I(x), Y: fixed.I(y)}
 
@@ -34,6 +80,51 @@ func (s *ImageDrawable) DrawString(x, y int, text string, c color.Color) {
 	}
 	fd.DrawString(text)
 }
+
+//Set implements Drawable interface
 func (s *ImageDrawable) Set(x, y int, c color.Color) {
 	s.img.Set(x, y, c)
 }
+
+//DrawLine implements VectorDrawable interface. Horizontal and vertical lines are filled at once
+func (s *ImageDrawable) DrawLine(x1, y1, x2, y2 int, c color.Color) {
+	if x1 == x2 || y1 == y2 {
+		s.FillRect(lineRect(x1, y1, x2, y2), c)
+		return
+	}
+	rasterizeLine(x1, y1, x2, y2, func(x, y int) {
+		s.img.Set(x, y, c)
+	})
+}
+
+//FillRect implements VectorDrawable interface
+func (s *ImageDrawable) FillRect(r image.Rectangle, c color.Color) {
+	draw.Draw(s.img, r.Canon(), image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+//DrawPolyline implements VectorDrawable interface
+func (s *ImageDrawable) DrawPolyline(points []image.Point, c color.Color) {
+	rasterizePolyline(points, func(x1, y1, x2, y2 int) {
+		s.DrawLine(x1, y1, x2, y2, c)
+	})
+}
+
+//FillPolygon implements VectorDrawable interface
+func (s *ImageDrawable) FillPolygon(points []image.Point, c color.Color) {
+	rasterizePolygon(points, func(x1, x2, y int) {
+		s.FillRect(image.Rect(x1, y, x2, y+1), c)
+	})
+}
+
+//DrawText implements VectorDrawable interface
+func (s *ImageDrawable) DrawText(x, y int, text string, style TextStyle, c color.Color) {
+	style.withFace(func(face font.Face) {
+		fd := &font.Drawer{
+			Dst:  s.img,
+			Src:  image.NewUniform(c),
+			Face: face,
+			Dot:  fixed.P(x, y),
+		}
+		fd.DrawString(text)
+	})
+}
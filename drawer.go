@@ -1,28 +1,81 @@
+//Package go_hugipipes_signal_drawer draws waves, spectra and spectrograms of signals with musical note axes to images
+//and SVG documents.
+//
+//Figures can be built in code with NewDrawer or loaded from a FigureSpec. Specs are read and written as JSON only,
+//YAML isn't supported. A spec round-trips from JSON to a FigureSpec and back, but a Drawer can't be converted back to
+//a FigureSpec, because its widgets hold the data itself instead of the names referencing it in a FigureData
 package go_hugipipes_signal_drawer
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"runtime"
+	"sync"
+)
+
 type DrawerBuilder struct {
-	plotHeight int
-	plotWidth  int
-	labelSpace int
-	spacePart  int
-	plots      []DrawerWidget
-	drawable   Drawable
+	plotHeight   int
+	plotWidth    int
+	labelSpace   int
+	spacePart    int
+	plots        []DrawerWidget
+	cells        []GridCell
+	columnGutter int
+	rowGutter    int
+	font         *Font
+	titleSize    float64
+	axisSize     float64
+	tickSize     float64
+	theme        Theme
+	parallelism  int
+	progress     func(done int, total int)
+	drawable     Drawable
+	canvas       VectorDrawable
 }
 
 func NewDrawer() *DrawerBuilder {
 	return &DrawerBuilder{
-		plotHeight: 300,
-		plotWidth:  2000,
-		labelSpace: 80,
-		spacePart:  10,
-		plots:      make([]DrawerWidget, 0),
+		plotHeight:  300,
+		plotWidth:   2000,
+		labelSpace:  80,
+		spacePart:   10,
+		plots:       make([]DrawerWidget, 0),
+		cells:       make([]GridCell, 0),
+		font:        DefaultFont(),
+		titleSize:   16,
+		axisSize:    13,
+		tickSize:    12,
+		theme:       DarkTheme(),
+		parallelism: 1,
 	}
 
 }
 
-func (s *DrawerBuilder) Build() *Drawer {
+//Build creates the Drawer after validating the data of all plots. The returned error is a *PlotError
+func (s *DrawerBuilder) Build() (*Drawer, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
 	self := newDrawer(s)
-	return self
+	return self, nil
+}
+
+//validate checks the data of all plots and that all cells of the grid have space left for the plots
+func (s *DrawerBuilder) validate() error {
+	l := s.layout()
+	for i, p := range s.plots {
+		err := plotValidate(p)
+		size := p.Measure()
+		if b := l.bounds[i]; err == nil && (b.Dx() < size.MinWidth || b.Dy() < size.MinHeight) {
+			err = fmt.Errorf("cell of %dx%d pixels has no space left for the plot: %w", b.Dx(), b.Dy(), ErrZeroRange)
+		}
+		if err != nil {
+			return &PlotError{Index: i, Title: plotTitle(p), Err: err}
+		}
+	}
+	return nil
 }
 
 func (s *DrawerBuilder) LabelSpace(labelSpace int) *DrawerBuilder {
@@ -30,36 +83,141 @@ func (s *DrawerBuilder) LabelSpace(labelSpace int) *DrawerBuilder {
 	s.spacePart = labelSpace / 8
 	return s
 }
+
+//PlotWidth sets the width of the plots without the label space. Default is 2000
+func (s *DrawerBuilder) PlotWidth(plotWidth int) *DrawerBuilder {
+	s.plotWidth = plotWidth
+	return s
+}
+
+//PlotHeight sets the height of the plots without the label space. Default is 300
 func (s *DrawerBuilder) PlotHeight(plotHeight int) *DrawerBuilder {
 	s.plotHeight = plotHeight
 	return s
 }
 
+//Font sets the font of all text. Default is DefaultFont
+func (s *DrawerBuilder) Font(font *Font) *DrawerBuilder {
+	if font == nil {
+		return s
+	}
+	s.font = font
+	return s
+}
+
+//FontSizes sets the sizes in pixels of the plot titles, the axis labels like the frequency range and the tick labels
+//like notes, times and values. Default is 16, 13 and 12
+func (s *DrawerBuilder) FontSizes(title float64, axis float64, tick float64) *DrawerBuilder {
+	if title <= 0 || axis <= 0 || tick <= 0 {
+		return s
+	}
+	s.titleSize = title
+	s.axisSize = axis
+	s.tickSize = tick
+	return s
+}
+
+//titleStyle returns the style of the plot titles
+func (s *DrawerBuilder) titleStyle() TextStyle {
+	return TextStyle{Font: s.font, Size: s.titleSize}
+}
+
+//axisStyle returns the style of the axis labels
+func (s *DrawerBuilder) axisStyle() TextStyle {
+	return TextStyle{Font: s.font, Size: s.axisSize}
+}
+
+//tickStyle returns the style of the tick labels
+func (s *DrawerBuilder) tickStyle() TextStyle {
+	return TextStyle{Font: s.font, Size: s.tickSize}
+}
+
+//Theme sets the colors of all plots. Plots can replace it with their own theme or single colors. Default is DarkTheme
+func (s *DrawerBuilder) Theme(theme Theme) *DrawerBuilder {
+	s.theme = theme
+	return s
+}
+
+//Parallelism sets how many goroutines draw plots at the same time, 0 uses GOMAXPROCS. Plots are only drawn in
+//parallel to a ConcurrentDrawable like the image of Render and if no cells overlap, otherwise they are drawn one after
+//another. Widgets drawn in parallel must not share state, so add each widget only once. Default is 1
+func (s *DrawerBuilder) Parallelism(parallelism int) *DrawerBuilder {
+	s.parallelism = max(parallelism, 0)
+	return s
+}
+
+//Progress sets a function called after each plot is drawn with the number of drawn plots and the number of all plots.
+//With Parallelism it is called from the drawing goroutines, but never twice at the same time
+func (s *DrawerBuilder) Progress(progress func(done int, total int)) *DrawerBuilder {
+	s.progress = progress
+	return s
+}
+
+//Gutter sets the space in pixels between the columns and between the rows of the grid. Default is 0
+func (s *DrawerBuilder) Gutter(columnGutter int, rowGutter int) *DrawerBuilder {
+	s.columnGutter = max(columnGutter, 0)
+	s.rowGutter = max(rowGutter, 0)
+	return s
+}
+
+//AddPlot adds a plot in a new row below all other plots
 func (s *DrawerBuilder) AddPlot(plot DrawerWidget) *DrawerBuilder {
+	return s.AddPlotAt(plot, NewGridCell(s.nextRow(), 0))
+}
+
+//AddPlotAt adds a plot to the cell of the grid. Overlapping cells are drawn in the order they are added. A nil cell
+//adds the plot in a new row like AddPlot
+func (s *DrawerBuilder) AddPlotAt(plot DrawerWidget, cell *GridCell) *DrawerBuilder {
+	if cell == nil {
+		cell = NewGridCell(s.nextRow(), 0)
+	}
 	s.plots = append(s.plots, plot)
+	s.cells = append(s.cells, *cell)
 	return s
 }
 
-func (s *DrawerBuilder) GetHeight() int {
-	h := 0
-	for _, p := range s.plots {
-		h += p.getWidgetHeight()
+//AddRow adds plots side by side in a new row below all other plots
+func (s *DrawerBuilder) AddRow(plots ...DrawerWidget) *DrawerBuilder {
+	row := s.nextRow()
+	for i, p := range plots {
+		s.AddPlotAt(p, NewGridCell(row, i))
 	}
-	return h
+	return s
 }
-func (s *DrawerBuilder) GetWidth() int {
-	w := 0
-	for _, p := range s.plots {
-		w = max(w, p.getWidgetWidth())
+
+//AddColumn adds plots on top of each other in a new column right of all other plots
+func (s *DrawerBuilder) AddColumn(plots ...DrawerWidget) *DrawerBuilder {
+	column := s.nextColumn()
+	for i, p := range plots {
+		s.AddPlotAt(p, NewGridCell(i, column))
 	}
-	return w
+	return s
+}
+
+//GetHeight returns the height of the grid with all plots
+func (s *DrawerBuilder) GetHeight() int {
+	return s.layout().height
+}
+
+//GetWidth returns the width of the grid with all plots
+func (s *DrawerBuilder) GetWidth() int {
+	return s.layout().width
 }
 
 func (s *DrawerBuilder) SetDrawable(drawable Drawable) *DrawerBuilder {
 	s.drawable = drawable
+	s.canvas = NewVectorDrawable(drawable)
 	return s
 }
 
+//plotTitle returns the title of a plot if it has one
+func plotTitle(plot DrawerWidget) string {
+	if t, ok := plot.(interface{ getTitle() string }); ok {
+		return t.getTitle()
+	}
+	return ""
+}
+
 type Drawer struct {
 	*DrawerBuilder
 }
@@ -70,12 +228,120 @@ func newDrawer(builder *DrawerBuilder) *Drawer {
 	}
 }
 
-func (s *Drawer) Draw() {
-	y := 0
-	for _, p := range s.plots {
-		p.draw(y)
-		y += p.getWidgetHeight()
+//Draw draws all plots to the drawable. Nothing is drawn if the drawable is missing or any plot is invalid
+func (s *Drawer) Draw() error {
+	return s.DrawContext(context.Background())
+}
+
+//DrawContext draws like Draw, but stops soon after ctx is done and returns ctx.Err(). Plots are checked between each
+//other and while drawing their items, so the drawable can be drawn partially then
+func (s *Drawer) DrawContext(ctx context.Context) error {
+	if s.drawable == nil {
+		return ErrMissingDrawable
+	}
+	if err := s.validate(); err != nil {
+		return err
+	}
+	l := s.layout()
+	progress := &drawProgress{total: len(s.plots), report: s.progress}
+	if d, ok := s.drawable.(ConcurrentDrawable); ok && s.workers() > 1 && !overlapping(l.bounds) {
+		return s.drawParallel(ctx, d, l.bounds, progress)
+	}
+	for i, p := range s.plots {
+		if err := plotDraw(ctx, p, l.bounds[i], s.canvas); err != nil {
+			return s.plotError(ctx, i, err)
+		}
+		progress.plotDone()
 	}
+	return nil
+}
+
+//drawParallel draws the plots with a pool of goroutines, each plot to its own region of drawable. The error of the
+//first failing plot is returned after all started plots are done
+func (s *Drawer) drawParallel(ctx context.Context, drawable ConcurrentDrawable, bounds []image.Rectangle, progress *drawProgress) error {
+	errs := make([]error, len(s.plots))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := min(s.workers(), len(s.plots)); w > 0; w-- {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				errs[i] = plotDraw(ctx, s.plots[i], bounds[i], NewVectorDrawable(drawable.Region(bounds[i])))
+				if errs[i] == nil {
+					progress.plotDone()
+				}
+			}
+		}()
+	}
+	started := 0
+feed:
+	for started < len(s.plots) {
+		select {
+		case jobs <- started:
+			started++
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return s.plotError(ctx, i, err)
+		}
+	}
+	if started < len(s.plots) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+//plotError wraps the error of the plot at index into a *PlotError. Errors of a done ctx are returned as they are
+func (s *Drawer) plotError(ctx context.Context, index int, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		return ctxErr
+	}
+	return &PlotError{Index: index, Title: plotTitle(s.plots[index]), Err: err}
+}
+
+//drawProgress counts the drawn plots and reports them to the progress function of the DrawerBuilder
+type drawProgress struct {
+	mutex  sync.Mutex
+	done   int
+	total  int
+	report func(done int, total int)
+}
+
+//plotDone counts a drawn plot and reports the progress
+func (s *drawProgress) plotDone() {
+	if s.report == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.done++
+	s.report(s.done, s.total)
+}
+
+//workers returns the number of goroutines drawing plots
+func (s *Drawer) workers() int {
+	if s.parallelism == 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return s.parallelism
+}
+
+//overlapping returns if any two of the rectangles overlap
+func overlapping(bounds []image.Rectangle) bool {
+	for i, a := range bounds {
+		for _, b := range bounds[i+1:] {
+			if a.Overlaps(b) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func max(one int, two int) int {
@@ -84,3 +350,10 @@ func max(one int, two int) int {
 	}
 	return two
 }
+
+func min(one int, two int) int {
+	if one < two {
+		return one
+	}
+	return two
+}
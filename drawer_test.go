@@ -1,20 +1,299 @@
 package go_hugipipes_signal_drawer
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"math"
 	"testing"
 	"time"
 )
 
 func TestWidgets(t *testing.T) {
-	defer func() {
-		recover()
-	}()
 	spec := NewSpectrumDrawer(nil, make([]float64, 0), "")
 	checkDrawerWidgetInterface(spec)
 	tim := NewWaveDrawer(nil, make([]time.Duration, 0), "")
 	checkDrawerWidgetInterface(tim)
+	spectrogram := NewSpectrogramDrawer(nil, make([]time.Duration, 0), make([]float64, 0), make([][]float64, 0), "")
+	checkDrawerWidgetInterface(spectrogram)
+	colorbar := NewColorbarDrawer(NewDrawer(), 0, 1, "")
+	checkDrawerWidgetInterface(colorbar)
+	streaming := NewStreamingWaveDrawer(nil, nil, "")
+	checkDrawerWidgetInterface(streaming)
 }
 
 func checkDrawerWidgetInterface(i DrawerWidget) {
 
 }
+
+func TestDrawerValidation(t *testing.T) {
+	times := []time.Duration{0, time.Millisecond, 2 * time.Millisecond}
+	cases := []struct {
+		name string
+		plot func(d *DrawerBuilder) DrawerWidget
+		want error
+	}{
+		{"empty times", func(d *DrawerBuilder) DrawerWidget {
+			return NewWaveDrawer(d, nil, "")
+		}, ErrEmptyData},
+		{"length mismatch", func(d *DrawerBuilder) DrawerWidget {
+			return NewWaveDrawer(d, times, "").SetItems(NewWaveDrawerItems([]float64{1, 2}, red))
+		}, ErrLengthMismatch},
+		{"NaN", func(d *DrawerBuilder) DrawerWidget {
+			return NewWaveDrawer(d, times, "").SetItems(NewWaveDrawerItems([]float64{1, math.NaN(), 2}, red))
+		}, ErrInvalidValue},
+		{"zero range", func(d *DrawerBuilder) DrawerWidget {
+			return NewWaveDrawer(d, times[:1], "")
+		}, ErrZeroRange},
+		{"empty frequencies", func(d *DrawerBuilder) DrawerWidget {
+			return NewSpectrumDrawer(d, nil, "")
+		}, ErrEmptyData},
+	}
+	for _, c := range cases {
+		d := NewDrawer()
+		d.AddPlot(c.plot(d))
+		_, err := d.Build()
+		var plotErr *PlotError
+		if !errors.Is(err, c.want) || !errors.As(err, &plotErr) {
+			t.Errorf("%s: expected a *PlotError caused by %v, got %v", c.name, c.want, err)
+		}
+	}
+}
+
+func TestDrawerDraw(t *testing.T) {
+	d := NewDrawer()
+	times := []time.Duration{0, time.Millisecond, 2 * time.Millisecond}
+	d.AddPlot(NewWaveDrawer(d, times, "wave").SetItems(NewWaveDrawerItems([]float64{0, 1, -1}, red)))
+	d.AddPlot(NewSpectrumDrawer(d, []float64{100, 200, 300}, "spectrum").SetItems(NewSpectrumDrawerItems([]float64{1, 0.5, 0.25}, true, red)))
+	drawer, err := d.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := drawer.Draw(); !errors.Is(err, ErrMissingDrawable) {
+		t.Errorf("expected ErrMissingDrawable, got %v", err)
+	}
+	d.SetDrawable(NewImageDrawable(image.NewRGBA(image.Rect(0, 0, d.GetWidth()+1, d.GetHeight()+1))))
+	if err := drawer.Draw(); err != nil {
+		t.Errorf("expected drawing to succeed, got %v", err)
+	}
+}
+
+//panelWidget is a widget like it would be written outside of the package
+type panelWidget struct {
+	width   int
+	err     error
+	invalid bool
+	regions []image.Rectangle
+}
+
+func (s *panelWidget) Measure() Measurement {
+	return Measurement{Width: s.width, Height: 50, MinWidth: 10, MinHeight: 10}
+}
+
+func (s *panelWidget) Draw(region image.Rectangle, canvas VectorDrawable) error {
+	s.regions = append(s.regions, region)
+	canvas.FillRect(region, color.RGBA{R: 255, A: 255})
+	return s.err
+}
+
+func (s *panelWidget) Validate() error {
+	if s.invalid {
+		return ErrEmptyData
+	}
+	return nil
+}
+
+func TestCustomWidget(t *testing.T) {
+	d := NewDrawer().PlotWidth(100).PlotHeight(50).LabelSpace(20)
+	wave := NewWaveDrawer(d, []time.Duration{0, time.Millisecond}, "wave")
+	panel := &panelWidget{width: 60}
+	d.AddRow(wave, panel)
+
+	drawer, err := d.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := drawer.Render(); err != nil {
+		t.Fatal(err)
+	}
+	//The row is as high as the wave with its margins fitting the title and the time labels
+	if want := []image.Rectangle{image.Rect(140, 0, 200, wave.Measure().Height)}; len(panel.regions) != 1 || panel.regions[0] != want[0] {
+		t.Errorf("expected the panel to be drawn to %v, got %v", want, panel.regions)
+	}
+
+	panel.err = ErrInvalidValue
+	var plotErr *PlotError
+	if _, err := drawer.Render(); !errors.Is(err, ErrInvalidValue) || !errors.As(err, &plotErr) || plotErr.Index != 1 {
+		t.Errorf("expected the error of the panel as *PlotError, got %v", err)
+	}
+	panel.err = nil
+	panel.invalid = true
+	if _, err := d.Build(); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("expected the panel to be validated, got %v", err)
+	}
+	panel.invalid = false
+	d.AddPlotAt(&panelWidget{width: 60}, NewGridCell(1, 2).Size(5, 0))
+	if _, err := d.Build(); !errors.Is(err, ErrZeroRange) {
+		t.Errorf("expected a cell smaller than the minimum size to fail, got %v", err)
+	}
+}
+
+//newSpectraDrawer creates a figure of count spectra with 2000 pixel wide plots and a few other widgets
+func newSpectraDrawer(count int) *DrawerBuilder {
+	d := NewDrawer()
+	frequencies := make([]float64, 8192)
+	points := make([]float64, len(frequencies))
+	for i := range frequencies {
+		frequencies[i] = float64(i+1) * 2.5
+		points[i] = math.Abs(math.Sin(float64(i)/40)) / float64(i%7+1)
+	}
+	for i := 0; i < count; i++ {
+		d.AddPlot(NewSpectrumDrawer(d, frequencies, "spectrum").SetItems(NewSpectrumDrawerItems(points, true, nil).Name("input")))
+	}
+	return d
+}
+
+func TestParallelDraw(t *testing.T) {
+	d := newSpectraDrawer(3)
+	times := []time.Duration{0, time.Millisecond, 2 * time.Millisecond}
+	sg := NewSpectrogramDrawer(d, times, []float64{100, 200}, [][]float64{{1, 0.5}, {0.25, 0}, {0.5, 1}}, "spectrogram")
+	d.AddRow(sg, NewSpectrogramColorbar(d, sg, "dB"))
+	d.AddRow(NewWaveDrawer(d, times, "wave").SetItems(NewWaveDrawerItems([]float64{0, 1, -1}, nil)), &panelWidget{width: 60})
+	drawer, err := d.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := drawer.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, parallelism := range []int{0, 2, 16} {
+		d.Parallelism(parallelism)
+		got, err := drawer.Render()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.(*image.RGBA).Pix, want.(*image.RGBA).Pix) {
+			t.Errorf("expected parallelism %d to draw the same image as drawing one plot after another", parallelism)
+		}
+	}
+
+	var plotErr *PlotError
+	d.plots[len(d.plots)-1].(*panelWidget).err = ErrInvalidValue
+	if _, err := drawer.Render(); !errors.As(err, &plotErr) || plotErr.Index != len(d.plots)-1 {
+		t.Errorf("expected the error of the panel as *PlotError, got %v", err)
+	}
+}
+
+func TestDrawContext(t *testing.T) {
+	d := newSpectraDrawer(3)
+	var reports [][2]int
+	d.Progress(func(done int, total int) {
+		reports = append(reports, [2]int{done, total})
+	})
+	drawer, err := d.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := drawer.RenderContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := [][2]int{{1, 3}, {2, 3}, {3, 3}}; len(reports) != len(want) || reports[0] != want[0] || reports[2] != want[2] {
+		t.Errorf("expected progress %v, got %v", want, reports)
+	}
+
+	for _, parallelism := range []int{1, 4} {
+		d.Parallelism(parallelism)
+		ctx, cancel := context.WithCancel(context.Background())
+		reports = nil
+		d.Progress(func(done int, total int) {
+			reports = append(reports, [2]int{done, total})
+			cancel()
+		})
+		var plotErr *PlotError
+		_, err := drawer.RenderContext(ctx)
+		if (err != nil || parallelism == 1) && (!errors.Is(err, context.Canceled) || errors.As(err, &plotErr)) {
+			t.Errorf("expected parallelism %d to return context.Canceled, got %v", parallelism, err)
+		}
+		if parallelism == 1 && len(reports) != 1 {
+			t.Errorf("expected drawing to stop after the first plot, got progress %v", reports)
+		}
+		drawn := len(reports)
+		if _, err := drawer.RenderContext(ctx); !errors.Is(err, context.Canceled) || len(reports) != drawn {
+			t.Errorf("expected parallelism %d to draw nothing with a done context, got %v", parallelism, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	times := []time.Duration{0, time.Millisecond}
+	widgets := []ContextDrawerWidget{
+		NewWaveDrawer(d, times, "").SetItems(NewWaveDrawerItems([]float64{0, 1}, nil)),
+		NewSpectrumDrawer(d, []float64{100, 200}, "").SetItems(NewSpectrumDrawerItems([]float64{0, 1}, true, nil)),
+		NewSpectrogramDrawer(d, times, []float64{100, 200}, [][]float64{{0, 1}, {1, 0}}, ""),
+		NewColorbarDrawer(d, 0, 1, ""),
+	}
+	for i, w := range widgets {
+		img := image.NewRGBA(image.Rect(0, 0, 2200, 400))
+		if err := w.DrawContext(ctx, img.Bounds(), NewImageDrawable(img)); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected widget %d to return context.Canceled, got %v", i, err)
+		}
+	}
+
+	//Long waves are decimated to an envelope, which is cancelled while the samples are reduced
+	long := make([]time.Duration, 100000)
+	samples := make([]float64, len(long))
+	for i := range long {
+		long[i] = time.Duration(i) * time.Microsecond
+		samples[i] = math.Sin(float64(i) / 100)
+	}
+	envelopeColor := color.RGBA{R: 1, G: 254, B: 3, A: 255}
+	wave := NewWaveDrawer(d, long, "").SetItems(NewWaveDrawerItems(samples, envelopeColor))
+	img := image.NewRGBA(image.Rect(0, 0, 2200, 400))
+	if err := wave.DrawContext(&expiringContext{Context: context.Background(), checks: 2}, img.Bounds(), NewImageDrawable(img)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the decimated wave to return context.Canceled, got %v", err)
+	}
+	for i := 0; i < len(img.Pix); i += 4 {
+		if img.Pix[i] == envelopeColor.R && img.Pix[i+1] == envelopeColor.G && img.Pix[i+2] == envelopeColor.B {
+			t.Fatal("expected the envelope to be cancelled before it is drawn")
+		}
+	}
+}
+
+//expiringContext is a context that is done after Err was called checks times
+type expiringContext struct {
+	context.Context
+	checks int
+}
+
+//Err returns context.Canceled once all checks are used
+func (s *expiringContext) Err() error {
+	if s.checks <= 0 {
+		return context.Canceled
+	}
+	s.checks--
+	return nil
+}
+
+func BenchmarkDraw(b *testing.B) {
+	for _, parallelism := range []int{1, 0} {
+		name := "sequential"
+		if parallelism == 0 {
+			name = "parallel"
+		}
+		b.Run(name, func(b *testing.B) {
+			drawer, err := newSpectraDrawer(8).Parallelism(parallelism).Build()
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := drawer.Render(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,7 +1,60 @@
 package go_hugipipes_signal_drawer
 
+import (
+	"context"
+	"image"
+)
+
+//DrawerWidget is a plot in the grid of a DrawerBuilder. The built-in widgets like WaveDrawer implement it, so own
+//widgets can be added with AddPlot and compose with them. Widgets that implement Validate() error are checked by
+//DrawerBuilder.Build and Drawer.Draw before anything is drawn
 type DrawerWidget interface {
-	getWidgetHeight() int
-	getWidgetWidth() int
-	draw(y int)
+	//Measure returns the size the widget needs. It is called to lay out the grid before drawing
+	Measure() Measurement
+	//Draw draws the widget to region of canvas, which is the cell of the widget in the grid. The region is at least as
+	//large as the measured minimum size, but can be larger if other widgets in the same row or column are larger.
+	//Nothing must be drawn outside of region
+	Draw(region image.Rectangle, canvas VectorDrawable) error
+}
+
+//ContextDrawerWidget is a DrawerWidget that stops drawing when a context is done. Drawer.DrawContext calls
+//DrawContext instead of Draw for these widgets, all others are only cancelled between widgets. The built-in widgets
+//implement it
+type ContextDrawerWidget interface {
+	DrawerWidget
+	//DrawContext draws like Draw, but returns ctx.Err() soon after ctx is done. The region can be drawn partially then
+	DrawContext(ctx context.Context, region image.Rectangle, canvas VectorDrawable) error
+}
+
+//cancelCheckInterval is the number of points drawn between two checks if the context is done
+const cancelCheckInterval = 1024
+
+//Measurement is the size of a DrawerWidget in pixels
+type Measurement struct {
+	//Width and Height are the preferred size of the widget with all its margins
+	Width  int
+	Height int
+	//MinWidth and MinHeight are the smallest region the widget can be drawn to. Build fails with ErrZeroRange if the
+	//cell of the widget is smaller
+	MinWidth  int
+	MinHeight int
+}
+
+//plotValidate calls Validate of a plot if it has one
+func plotValidate(plot DrawerWidget) error {
+	if v, ok := plot.(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+//plotDraw draws a plot with DrawContext if it has one or with Draw unless ctx is done
+func plotDraw(ctx context.Context, plot DrawerWidget, region image.Rectangle, canvas VectorDrawable) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if c, ok := plot.(ContextDrawerWidget); ok {
+		return c.DrawContext(ctx, region, canvas)
+	}
+	return plot.Draw(region, canvas)
 }
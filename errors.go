@@ -0,0 +1,54 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	//ErrEmptyData is returned if a plot has no data to draw, like no times or frequencies
+	ErrEmptyData = errors.New("empty data")
+	//ErrLengthMismatch is returned if the points of an item don't match the times or frequencies of the plot
+	ErrLengthMismatch = errors.New("length mismatch")
+	//ErrInvalidValue is returned if the data of a plot contains NaN or infinite values
+	ErrInvalidValue = errors.New("invalid value")
+	//ErrMissingDrawable is returned if a Drawer is drawn before a Drawable is set
+	ErrMissingDrawable = errors.New("missing drawable")
+	//ErrZeroRange is returned if the shown range of a plot is empty, like equal start and end times
+	ErrZeroRange = errors.New("zero range")
+)
+
+//PlotError is returned by Drawer if one of its plots can't be drawn. Use errors.Is to check for the cause like
+//ErrEmptyData
+type PlotError struct {
+	//Index is the position of the plot in the Drawer
+	Index int
+	//Title is the title of the plot
+	Title string
+	//Err is the cause
+	Err error
+}
+
+//Error implements error interface
+func (s *PlotError) Error() string {
+	return fmt.Sprintf("plot %d %q: %v", s.Index, s.Title, s.Err)
+}
+
+//Unwrap returns the cause of the error
+func (s *PlotError) Unwrap() error {
+	return s.Err
+}
+
+//validatePoints checks that points has the expected length and contains only finite values
+func validatePoints(name string, points []float64, length int) error {
+	if len(points) != length {
+		return fmt.Errorf("%s has %d points instead of %d: %w", name, len(points), length, ErrLengthMismatch)
+	}
+	for i, v := range points {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("%s has value %f at %d: %w", name, v, i, ErrInvalidValue)
+		}
+	}
+	return nil
+}
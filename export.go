@@ -0,0 +1,94 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//ErrUnsupportedFormat is returned by SaveFile if the file extension is none of .png, .jpg, .jpeg or .svg
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+//Render draws all plots to a new image sized to fit all plots
+func (s *Drawer) Render() (image.Image, error) {
+	return s.RenderContext(context.Background())
+}
+
+//RenderContext renders like Render, but stops soon after ctx is done and returns ctx.Err(), see Drawer.DrawContext
+func (s *Drawer) RenderContext(ctx context.Context) (image.Image, error) {
+	img := image.NewRGBA(image.Rect(0, 0, s.GetWidth(), s.GetHeight()))
+	if err := s.drawTo(ctx, NewImageDrawable(img)); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+//WritePNG renders all plots and writes them to w as PNG
+func (s *Drawer) WritePNG(w io.Writer) error {
+	img, err := s.Render()
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+//WriteJPEG renders all plots and writes them to w as JPEG with quality between 1 and 100
+func (s *Drawer) WriteJPEG(w io.Writer, quality int) error {
+	img, err := s.Render()
+	if err != nil {
+		return err
+	}
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+}
+
+//WriteSVG draws all plots to a SVGDrawable sized to fit all plots and writes it to w
+func (s *Drawer) WriteSVG(w io.Writer) error {
+	svg := NewSVGDrawable(s.GetWidth(), s.GetHeight())
+	if err := s.drawTo(context.Background(), svg); err != nil {
+		return err
+	}
+	_, err := svg.WriteTo(w)
+	return err
+}
+
+//SaveFile draws all plots to the file at path. The format is chosen by the extension: .png, .jpg, .jpeg or .svg
+func (s *Drawer) SaveFile(path string) (err error) {
+	var write func(w io.Writer) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		write = s.WritePNG
+	case ".jpg", ".jpeg":
+		write = func(w io.Writer) error {
+			return s.WriteJPEG(w, jpeg.DefaultQuality)
+		}
+	case ".svg":
+		write = s.WriteSVG
+	default:
+		return fmt.Errorf("%s: %w", path, ErrUnsupportedFormat)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	return write(f)
+}
+
+//drawTo draws all plots to drawable and restores the previously set drawable afterwards
+func (s *Drawer) drawTo(ctx context.Context, drawable Drawable) error {
+	previous := s.drawable
+	s.SetDrawable(drawable)
+	defer s.SetDrawable(previous)
+	return s.DrawContext(ctx)
+}
@@ -0,0 +1,56 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestDrawer(t *testing.T) *Drawer {
+	d := NewDrawer().PlotHeight(100)
+	times := []time.Duration{0, time.Millisecond, 2 * time.Millisecond}
+	d.AddPlot(NewWaveDrawer(d, times, "wave").SetItems(NewWaveDrawerItems([]float64{0, 1, -1}, red)))
+	drawer, err := d.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return drawer
+}
+
+func TestWritePNG(t *testing.T) {
+	drawer := newTestDrawer(t)
+	var buf bytes.Buffer
+	if err := drawer.WritePNG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != drawer.GetWidth() || img.Bounds().Dy() != drawer.GetHeight() {
+		t.Errorf("expected image of %dx%d, got %v", drawer.GetWidth(), drawer.GetHeight(), img.Bounds())
+	}
+	if drawer.drawable != nil {
+		t.Error("expected the drawable to be restored after rendering")
+	}
+}
+
+func TestSaveFile(t *testing.T) {
+	drawer := newTestDrawer(t)
+	dir := t.TempDir()
+	for _, name := range []string{"plot.png", "plot.JPG", "plot.svg"} {
+		path := filepath.Join(dir, name)
+		if err := drawer.SaveFile(path); err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+			t.Errorf("%s: expected a non-empty file", name)
+		}
+	}
+	if err := drawer.SaveFile(filepath.Join(dir, "plot.bmp")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
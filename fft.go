@@ -0,0 +1,73 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"math"
+	"math/bits"
+)
+
+//Window is a window function applied to the samples before the FFT to reduce spectral leakage
+type Window int
+
+const (
+	//WindowRectangular doesn't change the samples. It gives the best frequency resolution but the most leakage
+	WindowRectangular Window = iota
+	//WindowHann is a good compromise between frequency resolution and leakage. This is the default
+	WindowHann
+	//WindowBlackmanHarris has very low leakage, which shows quiet partials next to loud ones
+	WindowBlackmanHarris
+	//WindowFlatTop has a flat pass band, which shows the amplitudes of partials most accurately
+	WindowFlatTop
+)
+
+//coefficients returns the periodic window of length n
+func (s Window) coefficients(n int) []float64 {
+	w := make([]float64, n)
+	for i := range w {
+		p := 2 * math.Pi * float64(i) / float64(n)
+		switch s {
+		case WindowHann:
+			w[i] = 0.5 - 0.5*math.Cos(p)
+		case WindowBlackmanHarris:
+			w[i] = 0.35875 - 0.48829*math.Cos(p) + 0.14128*math.Cos(2*p) - 0.01168*math.Cos(3*p)
+		case WindowFlatTop:
+			w[i] = 0.21557895 - 0.41663158*math.Cos(p) + 0.277263158*math.Cos(2*p) - 0.083578947*math.Cos(3*p) + 0.006947368*math.Cos(4*p)
+		default:
+			w[i] = 1
+		}
+	}
+	return w
+}
+
+//nextPowerOfTwo returns the smallest power of two that is at least n
+func nextPowerOfTwo(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(n-1))
+}
+
+//fft transforms x in place with an iterative radix-2 Cooley-Tukey FFT. The length of x must be a power of two
+func fft(x []complex128) {
+	n := len(x)
+	if n <= 1 {
+		return
+	}
+	shift := 64 - bits.Len(uint(n-1))
+	for i := range x {
+		j := int(bits.Reverse64(uint64(i)) >> shift)
+		if j > i {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := -2 * math.Pi / float64(size)
+		for start := 0; start < n; start += size {
+			for k := 0; k < size/2; k++ {
+				sin, cos := math.Sincos(step * float64(k))
+				t := complex(cos, sin) * x[start+k+size/2]
+				x[start+k+size/2] = x[start+k] - t
+				x[start+k] += t
+			}
+		}
+	}
+}
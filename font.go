@@ -0,0 +1,149 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+//Font is a parsed TrueType or OpenType font used for the text of the plots. It is safe for concurrent use
+type Font struct {
+	name  string
+	font  *opentype.Font
+	mutex sync.Mutex
+	faces map[float64]font.Face
+}
+
+var (
+	defaultFont     *Font
+	defaultFontOnce sync.Once
+)
+
+//DefaultFont returns the bundled Go Regular font used if no font is set
+func DefaultFont() *Font {
+	defaultFontOnce.Do(func() {
+		f, err := ParseFont("Go", goregular.TTF)
+		if err != nil {
+			panic(err)
+		}
+		defaultFont = f
+	})
+	return defaultFont
+}
+
+//ParseFont parses a TrueType or OpenType font. name is the font family written to SVG documents, which is used by
+//viewers that have the font installed
+func ParseFont(name string, data []byte) (*Font, error) {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Font{
+		name:  name,
+		font:  f,
+		faces: make(map[float64]font.Face),
+	}, nil
+}
+
+//LoadFont reads the TrueType or OpenType font file at path. The file name without extension is used as name, see
+//ParseFont
+func LoadFont(path string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFont(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), data)
+}
+
+//Name returns the font family
+func (s *Font) Name() string {
+	return s.name
+}
+
+//Face returns a new face of the font with size in pixels. Faces are not safe for concurrent use
+func (s *Font) Face(size float64) font.Face {
+	face, err := opentype.NewFace(s.font, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		//NewFace only fails for invalid options
+		panic(err)
+	}
+	return face
+}
+
+//withFace calls f with the cached face of the font with size in pixels, so the glyphs are only rasterized once. The
+//font is locked while f is running, because faces aren't safe for concurrent use
+func (s *Font) withFace(size float64, f func(face font.Face)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	face, ok := s.faces[size]
+	if !ok {
+		face = s.Face(size)
+		s.faces[size] = face
+	}
+	f(face)
+}
+
+//TextStyle is a font with a size in pixels
+type TextStyle struct {
+	Font *Font
+	Size float64
+}
+
+//withFace calls f with the cached face of the style, see Font.withFace
+func (s TextStyle) withFace(f func(face font.Face)) {
+	if s.Font == nil {
+		DefaultFont().withFace(s.Size, f)
+		return
+	}
+	s.Font.withFace(s.Size, f)
+}
+
+//fontName returns the font family of the style
+func (s TextStyle) fontName() string {
+	if s.Font == nil {
+		return DefaultFont().Name()
+	}
+	return s.Font.Name()
+}
+
+//width returns the width in pixels of text
+func (s TextStyle) width(text string) int {
+	var width fixed.Int26_6
+	s.withFace(func(face font.Face) {
+		width = font.MeasureString(face, text)
+	})
+	return width.Ceil()
+}
+
+//metrics returns the metrics of the face of the style
+func (s TextStyle) metrics() font.Metrics {
+	var metrics font.Metrics
+	s.withFace(func(face font.Face) {
+		metrics = face.Metrics()
+	})
+	return metrics
+}
+
+//ascent returns the height in pixels of the text above the baseline
+func (s TextStyle) ascent() int {
+	return s.metrics().Ascent.Ceil()
+}
+
+//descent returns the height in pixels of the text below the baseline
+func (s TextStyle) descent() int {
+	return s.metrics().Descent.Ceil()
+}
+
+//lineHeight returns the distance in pixels between the baselines of two lines
+func (s TextStyle) lineHeight() int {
+	return s.metrics().Height.Ceil()
+}
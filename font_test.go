@@ -0,0 +1,90 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"bytes"
+	"golang.org/x/image/font/gofont/goregular"
+	"image"
+	"image/color"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFontMeasuresText(t *testing.T) {
+	if _, err := ParseFont("broken", []byte("no font")); err == nil {
+		t.Error("expected an error for invalid font data")
+	}
+	small := TextStyle{Font: DefaultFont(), Size: 12}
+	large := TextStyle{Font: DefaultFont(), Size: 24}
+	if w := small.width("C4"); w <= 0 || large.width("C4") < 2*w-2 {
+		t.Errorf("expected text to scale with the size, got %d and %d", w, large.width("C4"))
+	}
+	if small.ascent() <= 0 || small.lineHeight() < small.ascent() {
+		t.Errorf("unexpected metrics: ascent %d, descent %d, line height %d", small.ascent(), small.descent(), small.lineHeight())
+	}
+}
+
+func TestFontCachesFaces(t *testing.T) {
+	f, err := ParseFont("Go", goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	style := TextStyle{Font: f, Size: 14}
+	want := style.width("A4 440Hz")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			img := image.NewRGBA(image.Rect(0, 0, 100, 20))
+			for j := 0; j < 20; j++ {
+				if w := style.width("A4 440Hz"); w != want {
+					t.Errorf("expected width %d, got %d", want, w)
+				}
+				NewImageDrawable(img).DrawText(0, 15, "A4", style, yellow)
+			}
+		}()
+	}
+	wg.Wait()
+	(TextStyle{Font: f, Size: 20}).ascent()
+	if len(f.faces) != 2 {
+		t.Errorf("expected one face per size, got %d", len(f.faces))
+	}
+}
+
+func TestDrawText(t *testing.T) {
+	style := TextStyle{Font: DefaultFont(), Size: 20}
+
+	img := image.NewRGBA(image.Rect(0, 0, 100, 40))
+	NewImageDrawable(img).DrawText(5, 25, "Hz", style, yellow)
+	set := 0
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] != 0 {
+			set++
+		}
+	}
+	if set == 0 {
+		t.Error("expected text to be drawn to the image")
+	}
+
+	pixels := &pixelDrawable{pixels: make(map[image.Point]color.Color)}
+	NewVectorDrawable(pixels).DrawText(5, 25, "Hz", style, yellow)
+	if len(pixels.pixels) == 0 || len(pixels.pixels) > set {
+		t.Errorf("expected at most the %d pixels of the image to be set, got %d", set, len(pixels.pixels))
+	}
+	for p := range pixels.pixels {
+		if p.Y > 25 || p.X < 5 {
+			t.Errorf("pixel %v is outside of the text", p)
+		}
+	}
+
+	svg := NewSVGDrawable(100, 40)
+	svg.DrawText(5, 25, "Hz", style, yellow)
+	var buf bytes.Buffer
+	if _, err := svg.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `font-family="Go, sans-serif" font-size="20"`) {
+		t.Errorf("expected font in the SVG: %s", buf.String())
+	}
+}
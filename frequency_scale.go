@@ -0,0 +1,83 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"fmt"
+	"math"
+)
+
+//FrequencyScale defines how frequencies are distributed along a frequency axis
+type FrequencyScale int
+
+const (
+	//FrequencyScaleLinear distributes the frequencies linearly between the start and end frequency. This is the default
+	FrequencyScaleLinear FrequencyScale = iota
+	//FrequencyScaleLog2 gives every octave the same width
+	FrequencyScaleLog2
+	//FrequencyScaleLog10 gives every octave the same width like FrequencyScaleLog2 and additionally draws a grid line at
+	//every decade (10Hz, 100Hz, 1kHz, ...)
+	FrequencyScaleLog10
+)
+
+//minLogFrequency is the lowest frequency shown on a logarithmic scale, as 0Hz can't be shown
+const minLogFrequency = 1.0
+
+//isLogarithmic returns true for all logarithmic scales
+func (s FrequencyScale) isLogarithmic() bool {
+	return s == FrequencyScaleLog2 || s == FrequencyScaleLog10
+}
+
+//transform maps a frequency to the scale. Positions on the axis are linear in the transformed value. Frequencies that
+//can't be shown on a logarithmic scale return NaN
+func (s FrequencyScale) transform(freq float64) float64 {
+	switch s {
+	case FrequencyScaleLog2:
+		if freq <= 0 {
+			return math.NaN()
+		}
+		return math.Log2(freq)
+	case FrequencyScaleLog10:
+		if freq <= 0 {
+			return math.NaN()
+		}
+		return math.Log10(freq)
+	default:
+		return freq
+	}
+}
+
+//inverse maps a transformed value back to a frequency
+func (s FrequencyScale) inverse(v float64) float64 {
+	switch s {
+	case FrequencyScaleLog2:
+		return math.Pow(2, v)
+	case FrequencyScaleLog10:
+		return math.Pow(10, v)
+	default:
+		return v
+	}
+}
+
+//lowerBound returns the lowest frequency that can be shown on the scale starting at startFreq
+func (s FrequencyScale) lowerBound(startFreq float64) float64 {
+	if s.isLogarithmic() {
+		return math.Max(startFreq, minLogFrequency)
+	}
+	return startFreq
+}
+
+//decades returns all powers of ten between startFreq and endFreq
+func decades(startFreq float64, endFreq float64) []float64 {
+	d := make([]float64, 0)
+	for f := math.Pow(10, math.Ceil(math.Log10(math.Max(startFreq, minLogFrequency)))); f <= endFreq; f *= 10 {
+		d = append(d, f)
+	}
+	return d
+}
+
+//formatFrequency formats a frequency as Hz or kHz without unnecessary decimals
+func formatFrequency(freq float64) string {
+	if freq >= 1000 {
+		return fmt.Sprintf("%gkHz", freq/1000)
+	}
+	return fmt.Sprintf("%gHz", freq)
+}
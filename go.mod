@@ -6,3 +6,5 @@ require (
 	github.com/michaelhugi/go-hugipipes-musical-notes v1.0.2
 	golang.org/x/image v0.0.0-20220321031419-a8550c1d254a
 )
+
+require golang.org/x/text v0.3.6 // indirect
@@ -0,0 +1,139 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"image"
+	"sort"
+)
+
+//GridCell places a plot in the grid of a DrawerBuilder. A cell spans one row and one column and is sized to fit its
+//plot by default. Columns and rows grow to fit their biggest cell, plots are stretched to fill their cells
+type GridCell struct {
+	row        int
+	column     int
+	rowSpan    int
+	columnSpan int
+	width      int
+	height     int
+}
+
+//NewGridCell is the constructor for GridCell at row and column, both starting at 0
+func NewGridCell(row int, column int) *GridCell {
+	return &GridCell{
+		row:        max(row, 0),
+		column:     max(column, 0),
+		rowSpan:    1,
+		columnSpan: 1,
+	}
+}
+
+//Span sets the number of rows and columns covered by the cell. Default is 1 for both
+func (s *GridCell) Span(rows int, columns int) *GridCell {
+	if rows < 1 || columns < 1 {
+		return s
+	}
+	s.rowSpan = rows
+	s.columnSpan = columns
+	return s
+}
+
+//Size sets the size of the cell including the label space instead of the size of its plot. A value of 0 keeps the
+//size of the plot
+func (s *GridCell) Size(width int, height int) *GridCell {
+	s.width = max(width, 0)
+	s.height = max(height, 0)
+	return s
+}
+
+//gridSpan is the extent of a cell along one axis of the grid
+type gridSpan struct {
+	start int
+	span  int
+	size  int
+}
+
+//gridTracks calculates the offsets of all columns or rows, the last one being the end of the grid plus one gutter.
+//Every track is as big as its biggest single cell, space missing for spanning cells is distributed evenly among their
+//tracks
+func gridTracks(spans []gridSpan, gutter int) []int {
+	count := 0
+	for _, sp := range spans {
+		count = max(count, sp.start+sp.span)
+	}
+	sorted := make([]gridSpan, len(spans))
+	copy(sorted, spans)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].span < sorted[j].span
+	})
+	sizes := make([]int, count)
+	for _, sp := range sorted {
+		missing := sp.size - (sp.span-1)*gutter
+		for i := sp.start; i < sp.start+sp.span; i++ {
+			missing -= sizes[i]
+		}
+		for i := 0; missing > 0 && i < sp.span; i++ {
+			share := missing / (sp.span - i)
+			sizes[sp.start+i] += share
+			missing -= share
+		}
+	}
+	offsets := make([]int, count+1)
+	for i, size := range sizes {
+		offsets[i+1] = offsets[i] + size + gutter
+	}
+	return offsets
+}
+
+//gridLayout contains the bounds of every plot and the size of the whole grid
+type gridLayout struct {
+	bounds []image.Rectangle
+	width  int
+	height int
+}
+
+//layout calculates the bounds of all plots in the grid
+func (s *DrawerBuilder) layout() gridLayout {
+	columns := make([]gridSpan, len(s.plots))
+	rows := make([]gridSpan, len(s.plots))
+	for i, p := range s.plots {
+		c := s.cells[i]
+		size := p.Measure()
+		width, height := c.width, c.height
+		if width == 0 {
+			width = size.Width
+		}
+		if height == 0 {
+			height = size.Height
+		}
+		columns[i] = gridSpan{start: c.column, span: c.columnSpan, size: width}
+		rows[i] = gridSpan{start: c.row, span: c.rowSpan, size: height}
+	}
+	xs := gridTracks(columns, s.columnGutter)
+	ys := gridTracks(rows, s.rowGutter)
+	l := gridLayout{
+		bounds: make([]image.Rectangle, len(s.plots)),
+		width:  max(xs[len(xs)-1]-s.columnGutter, 0),
+		height: max(ys[len(ys)-1]-s.rowGutter, 0),
+	}
+	for i, c := range s.cells {
+		l.bounds[i] = image.Rect(xs[c.column], ys[c.row], xs[c.column+c.columnSpan]-s.columnGutter, ys[c.row+c.rowSpan]-s.rowGutter)
+	}
+	return l
+}
+
+//nextRow returns the first row below all cells
+func (s *DrawerBuilder) nextRow() int {
+	row := 0
+	for _, c := range s.cells {
+		row = max(row, c.row+c.rowSpan)
+	}
+	return row
+}
+
+//nextColumn returns the first column right of all cells
+func (s *DrawerBuilder) nextColumn() int {
+	column := 0
+	for _, c := range s.cells {
+		column = max(column, c.column+c.columnSpan)
+	}
+	return column
+}
@@ -0,0 +1,64 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"errors"
+	"image"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGridTracks(t *testing.T) {
+	spans := []gridSpan{
+		{start: 0, span: 1, size: 100},
+		{start: 1, span: 1, size: 50},
+		{start: 0, span: 3, size: 400},
+	}
+	//Column 2 only gets space of the spanning cell: 400 - 100 - 50 - 2*10 = 230, distributed evenly to all 3 columns
+	want := []int{0, 100 + 76 + 10, 100 + 76 + 10 + 50 + 77 + 10, 100 + 76 + 10 + 50 + 77 + 10 + 77 + 10}
+	if got := gridTracks(spans, 10); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected offsets %v, got %v", want, got)
+	}
+}
+
+func TestGridLayout(t *testing.T) {
+	times := []time.Duration{0, time.Millisecond, 2 * time.Millisecond}
+	d := NewDrawer().PlotWidth(200).PlotHeight(100).LabelSpace(40).Gutter(10, 5)
+	wave := NewWaveDrawer(d, times, "wave").SetItems(NewWaveDrawerItems([]float64{0, 1, -1}, red))
+	spectrum := NewSpectrumDrawer(d, []float64{100, 200, 300}, "spectrum")
+	overview := NewWaveDrawer(d, times, "overview")
+	d.AddRow(wave, spectrum).AddPlotAt(overview, NewGridCell(1, 0).Span(1, 2).Size(0, 120))
+
+	//The first row is as high as the spectrum, whose bottom margin fits the notes and the frequency range
+	l := d.layout()
+	want := []image.Rectangle{
+		image.Rect(0, 0, 280, 186),
+		image.Rect(290, 0, 570, 186),
+		image.Rect(0, 191, 570, 311),
+	}
+	if !reflect.DeepEqual(l.bounds, want) {
+		t.Errorf("expected bounds %v, got %v", want, l.bounds)
+	}
+	if d.GetWidth() != 570 || d.GetHeight() != 311 {
+		t.Errorf("expected size 570x311, got %dx%d", d.GetWidth(), d.GetHeight())
+	}
+	drawer, err := d.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := drawer.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	d.AddPlotAt(NewWaveDrawer(d, times, "nil cell"), nil)
+	if c := d.cells[len(d.cells)-1]; c != *NewGridCell(2, 0) {
+		t.Errorf("expected a nil cell to be added in a new row, got %+v", c)
+	}
+
+	d.AddColumn(NewWaveDrawer(d, times, "too small"))
+	d.cells[len(d.cells)-1].Size(60, 60)
+	var plotErr *PlotError
+	if _, err := d.Build(); !errors.Is(err, ErrZeroRange) || !errors.As(err, &plotErr) || plotErr.Index != 4 {
+		t.Errorf("expected a *PlotError for the too small cell, got %v", err)
+	}
+}
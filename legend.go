@@ -0,0 +1,107 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"image"
+	"image/color"
+)
+
+//LegendPosition defines where the legend with the names of the items and marks of a plot is drawn
+type LegendPosition int
+
+const (
+	//LegendTopRight draws the legend inside the top right corner of the plot. This is the default
+	LegendTopRight LegendPosition = iota
+	//LegendTopLeft draws the legend inside the top left corner of the plot
+	LegendTopLeft
+	//LegendBottomLeft draws the legend inside the bottom left corner of the plot
+	LegendBottomLeft
+	//LegendBottomRight draws the legend inside the bottom right corner of the plot
+	LegendBottomRight
+	//LegendOutsideRight draws the legend right of the plot. The right margin is widened to fit the legend
+	LegendOutsideRight
+	//LegendHidden draws no legend
+	LegendHidden
+)
+
+//legendEntry is the name and color of one item or mark in a legend
+type legendEntry struct {
+	name  string
+	color color.Color
+}
+
+//legend draws a box with the names and colors of the items and marks of a plot. Only named entries are shown, so
+//plots without names have no legend
+type legend struct {
+	position   LegendPosition
+	entries    []legendEntry
+	style      TextStyle
+	padding    int
+	background color.Color
+	border     color.Color
+	textColor  color.Color
+}
+
+//visible returns if the legend has any entries to draw
+func (s legend) visible() bool {
+	return s.position != LegendHidden && len(s.entries) > 0
+}
+
+//size returns the width and height of the legend box
+func (s legend) size() (int, int) {
+	swatch := s.style.ascent()
+	textWidth := 0
+	for _, e := range s.entries {
+		textWidth = max(textWidth, s.style.width(e.name))
+	}
+	return swatch + textWidth + 3*s.padding, len(s.entries)*s.style.lineHeight() + 2*s.padding
+}
+
+//margins returns m with the right margin widened to fit the legend if it is drawn outside right of the plot
+func (s legend) margins(m margins) margins {
+	if s.position != LegendOutsideRight || !s.visible() {
+		return m
+	}
+	width, _ := s.size()
+	m.right = max(m.right, width+2*s.padding)
+	return m
+}
+
+//bounds returns the pixels covered by the legend including its border, drawn to a corner of plot or right of it
+//according to the position
+func (s legend) bounds(plot image.Rectangle) image.Rectangle {
+	width, height := s.size()
+	var x, y int
+	switch s.position {
+	case LegendTopLeft:
+		x, y = plot.Min.X+s.padding, plot.Min.Y+s.padding
+	case LegendBottomLeft:
+		x, y = plot.Min.X+s.padding, plot.Max.Y-s.padding-height
+	case LegendBottomRight:
+		x, y = plot.Max.X-s.padding-width, plot.Max.Y-s.padding-height
+	case LegendOutsideRight:
+		x, y = plot.Max.X+s.padding, plot.Min.Y
+	default:
+		x, y = plot.Max.X-s.padding-width, plot.Min.Y+s.padding
+	}
+	return image.Rect(x, y, x+width+1, y+height+1)
+}
+
+//draw draws the legend to a corner of plot or right of it according to the position
+func (s legend) draw(canvas VectorDrawable, plot image.Rectangle) {
+	if !s.visible() {
+		return
+	}
+	bounds := s.bounds(plot)
+	box := image.Rect(bounds.Min.X, bounds.Min.Y, bounds.Max.X-1, bounds.Max.Y-1)
+	x, y := box.Min.X, box.Min.Y
+	canvas.FillRect(box, s.background)
+	canvas.DrawPolyline([]image.Point{box.Min, {X: box.Max.X, Y: box.Min.Y}, box.Max, {X: box.Min.X, Y: box.Max.Y}, box.Min}, s.border)
+
+	swatch := s.style.ascent()
+	baseline := y + s.padding + s.style.ascent()
+	for _, e := range s.entries {
+		canvas.FillRect(image.Rect(x+s.padding, baseline-swatch, x+s.padding+swatch, baseline), e.color)
+		canvas.DrawText(x+2*s.padding+swatch, baseline, e.name, s.style, s.textColor)
+		baseline += s.style.lineHeight()
+	}
+}
@@ -0,0 +1,71 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestLegendPosition(t *testing.T) {
+	plot := image.Rect(100, 50, 500, 250)
+	for _, position := range []LegendPosition{LegendTopRight, LegendTopLeft, LegendBottomLeft, LegendBottomRight, LegendOutsideRight} {
+		l := legend{
+			position:   position,
+			entries:    []legendEntry{{name: "left", color: yellow}, {name: "right", color: gray}},
+			style:      TextStyle{Font: DefaultFont(), Size: 12},
+			padding:    10,
+			background: image.Black.C,
+			border:     gray,
+			textColor:  image.White.C,
+		}
+		pixels := &pixelDrawable{pixels: make(map[image.Point]color.Color)}
+		l.draw(NewVectorDrawable(pixels), plot)
+		drawn := image.Rectangle{}
+		for p := range pixels.pixels {
+			drawn = drawn.Union(image.Rectangle{Min: p, Max: p.Add(image.Pt(1, 1))})
+		}
+		inside := position != LegendOutsideRight
+		if drawn.In(plot) != inside {
+			t.Errorf("legend %d drawn to %v, expected inside the plot %v: %v", position, drawn, plot, inside)
+		}
+		width, height := l.size()
+		if drawn.Dx() != width+1 || drawn.Dy() != height+1 {
+			t.Errorf("legend %d drawn to %v, expected a size of %dx%d", position, drawn, width, height)
+		}
+		if m := l.margins(margins{right: 5}); inside && m.right != 5 || !inside && m.right < width {
+			t.Errorf("legend %d has right margin %d", position, m.right)
+		}
+	}
+}
+
+func TestWidgetLegend(t *testing.T) {
+	d := NewDrawer().PlotWidth(400).PlotHeight(100).LabelSpace(40)
+	wave := NewWaveDrawer(d, []time.Duration{0, time.Millisecond}, "wave").
+		SetItems(NewWaveDrawerItems([]float64{0, 1}, yellow))
+	if wave.legend().visible() {
+		t.Error("expected no legend without names")
+	}
+	wave.SetItems(NewWaveDrawerItems([]float64{1, 0}, gray).Name("right"))
+	if l := wave.legend(); !l.visible() || len(l.entries) != 1 || l.entries[0].name != "right" {
+		t.Errorf("expected a legend with the named item, got %+v", l.entries)
+	}
+	if w := wave.Measure().Width; w != 480 {
+		t.Errorf("expected a width of 480 with the legend inside, got %d", w)
+	}
+	wave.Legend(LegendOutsideRight)
+	width, _ := wave.legend().size()
+	if w := wave.Measure().Width; w != 440+width+2*d.spacePart {
+		t.Errorf("expected the right margin to fit the legend, got a width of %d", w)
+	}
+
+	spectrum := NewSpectrumDrawer(d, []float64{100, 200}, "spectrum").
+		SetItems(NewSpectrumDrawerItems([]float64{0, 1}, true, yellow).Name("magnitude")).
+		SetMark(NewSpectrumDrawerMark(440, gray).Name("A4"))
+	if l := spectrum.legend(); len(l.entries) != 2 || l.entries[1].name != "A4" {
+		t.Errorf("expected items and marks in the legend, got %+v", l.entries)
+	}
+	if spectrum.Legend(LegendHidden).legend().visible() {
+		t.Error("expected a hidden legend")
+	}
+}
@@ -0,0 +1,397 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"image/png"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//ErrUnknownDrawer is returned by Server.Update if no Drawer is registered with the name
+var ErrUnknownDrawer = errors.New("unknown drawer")
+
+const (
+	//maxServerPlotSize is the largest width and height in pixels of the plots a Server renders for a request
+	maxServerPlotSize = 10000
+	//maxServerPixels is the largest number of pixels of all plots together a Server renders for a request
+	maxServerPixels = 25000000
+)
+
+//Server is an http.Handler showing registered Drawers in a browser. For a Drawer registered as name it serves
+//	/name         a HTML page with the plots, reloaded whenever Update is called
+//	/name.png     the plots rendered to a PNG image
+//	/name.svg     the plots drawn to a SVG document
+//	/name/events  Server-Sent Events with the number of updates, sent on every Update
+//The images accept the query parameters startFreq and endFreq in Hz, startTime and endTime as durations like 1.5s and
+//width and height of the plots in pixels. They only apply to the request, the registered Drawer isn't changed. The
+//page passes its query parameters on to the image and shows the SVG with format=svg.
+//Mount it with http.StripPrefix to serve it below a path
+type Server struct {
+	mutex   sync.Mutex
+	drawers map[string]*serverDrawer
+}
+
+//serverDrawer is a Drawer registered in a Server with the clients waiting for its updates
+type serverDrawer struct {
+	//mutex is held while the drawer is rendered or updated
+	mutex   sync.Mutex
+	drawer  *Drawer
+	version int
+	clients map[chan int]bool
+}
+
+//NewServer is the constructor for Server
+func NewServer() *Server {
+	return &Server{
+		drawers: make(map[string]*serverDrawer),
+	}
+}
+
+//Register serves drawer as name, which must not contain a slash. A Drawer registered before with the same name is
+//replaced
+func (s *Server) Register(name string, drawer *Drawer) *Server {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.drawers[name] = &serverDrawer{drawer: drawer, clients: make(map[chan int]bool)}
+	return s
+}
+
+//Update calls update with the Drawer registered as name and reloads the plots in all browsers showing it. Pushing new
+//data into the widgets within update is safe while the plots are rendered for other requests. update can be nil if
+//the data was changed already
+func (s *Server) Update(name string, update func(drawer *Drawer)) error {
+	entry := s.lookup(name)
+	if entry == nil {
+		return fmt.Errorf("%s: %w", name, ErrUnknownDrawer)
+	}
+	if update != nil {
+		entry.mutex.Lock()
+		update(entry.drawer)
+		entry.mutex.Unlock()
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	entry.version++
+	for client := range entry.clients {
+		//Only the latest version is of interest for clients that didn't receive the previous one yet
+		select {
+		case <-client:
+		default:
+		}
+		client <- entry.version
+	}
+	return nil
+}
+
+//lookup returns the Drawer registered as name or nil
+func (s *Server) lookup(name string) *serverDrawer {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.drawers[name]
+}
+
+//ServeHTTP implements http.Handler interface
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	serve := s.servePage
+	switch {
+	case strings.HasSuffix(name, "/events"):
+		name = strings.TrimSuffix(name, "/events")
+		serve = s.serveEvents
+	case path.Ext(name) == ".png" || path.Ext(name) == ".svg":
+		name = strings.TrimSuffix(name, path.Ext(name))
+		serve = s.serveImage
+	}
+	entry := s.lookup(name)
+	if entry == nil {
+		http.NotFound(w, r)
+		return
+	}
+	serve(w, r, name, entry)
+}
+
+//serverPage is the HTML page showing the plots of a Drawer. The image is reloaded on every event
+var serverPage = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+<style>body { margin: 0; background: #808080; } img { display: block; max-width: 100%; }</style>
+</head>
+<body>
+<img id="plot" src="{{.Image}}" alt="{{.Name}}">
+<script>
+const plot = document.getElementById("plot");
+new EventSource({{.Events}}).onmessage = function (event) {
+	const src = new URL(plot.src);
+	src.searchParams.set("version", event.data);
+	plot.src = src.href;
+};
+</script>
+</body>
+</html>
+`))
+
+//servePage writes the HTML page showing the plots of a Drawer
+func (s *Server) servePage(w http.ResponseWriter, r *http.Request, name string, _ *serverDrawer) {
+	query := r.URL.Query()
+	format := ".png"
+	if query.Get("format") == "svg" {
+		format = ".svg"
+	}
+	query.Del("format")
+	imageURL := url.URL{Path: "./" + name + format, RawQuery: query.Encode()}
+	eventsURL := url.URL{Path: "./" + name + "/events"}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := serverPage.Execute(w, map[string]string{"Name": name, "Image": imageURL.String(), "Events": eventsURL.String()})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+//serveImage renders the plots of a Drawer to PNG or SVG with the view of the query parameters
+func (s *Server) serveImage(w http.ResponseWriter, r *http.Request, _ string, entry *serverDrawer) {
+	view, err := parsePlotView(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var buf bytes.Buffer
+	contentType := "image/png"
+	if path.Ext(r.URL.Path) == ".svg" {
+		contentType = "image/svg+xml"
+	}
+	entry.mutex.Lock()
+	drawer := view.apply(entry.drawer)
+	//The size of the canvas is checked before it is allocated, as every plot may have the largest size on its own
+	if pixels := drawer.GetWidth() * drawer.GetHeight(); pixels > maxServerPixels {
+		entry.mutex.Unlock()
+		err = fmt.Errorf("%dx%d pixels are more than %d: %w", drawer.GetWidth(), drawer.GetHeight(), maxServerPixels, ErrInvalidValue)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = renderImage(r.Context(), drawer, contentType, &buf)
+	entry.mutex.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = buf.WriteTo(w)
+}
+
+//renderImage draws drawer as contentType to buf
+func renderImage(ctx context.Context, drawer *Drawer, contentType string, buf *bytes.Buffer) error {
+	if contentType == "image/svg+xml" {
+		svg := NewSVGDrawable(drawer.GetWidth(), drawer.GetHeight())
+		if err := drawer.drawTo(ctx, svg); err != nil {
+			return err
+		}
+		_, err := svg.WriteTo(buf)
+		return err
+	}
+	img, err := drawer.RenderContext(ctx)
+	if err != nil {
+		return err
+	}
+	return png.Encode(buf, img)
+}
+
+//serveEvents streams an event with the number of updates of a Drawer until the client disconnects
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request, _ string, entry *serverDrawer) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	client := make(chan int, 1)
+	s.mutex.Lock()
+	entry.clients[client] = true
+	version := entry.version
+	s.mutex.Unlock()
+	defer func() {
+		s.mutex.Lock()
+		delete(entry.clients, client)
+		s.mutex.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	fmt.Fprintf(w, "retry: 1000\n\n")
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case v := <-client:
+			if v == version {
+				continue
+			}
+			version = v
+			if _, err := fmt.Fprintf(w, "data: %d\n\n", v); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+//plotView contains the ranges and size of a request to a Server. Nil fields keep the ones of the widgets
+type plotView struct {
+	startFreq *float64
+	endFreq   *float64
+	startTime *time.Duration
+	endTime   *time.Duration
+	width     int
+	height    int
+}
+
+//parsePlotView reads a plotView from the query parameters of a request
+func parsePlotView(query url.Values) (plotView, error) {
+	var v plotView
+	var err error
+	parseFreq := func(key string) *float64 {
+		value := query.Get(key)
+		if value == "" || err != nil {
+			return nil
+		}
+		f, perr := strconv.ParseFloat(value, 64)
+		if perr != nil || f < 0 {
+			err = fmt.Errorf("%s %q is no frequency in Hz: %w", key, value, ErrInvalidValue)
+			return nil
+		}
+		return &f
+	}
+	parseTime := func(key string) *time.Duration {
+		value := query.Get(key)
+		if value == "" || err != nil {
+			return nil
+		}
+		t, perr := time.ParseDuration(value)
+		if perr != nil {
+			err = fmt.Errorf("%s %q is no duration like 1.5s: %w", key, value, ErrInvalidValue)
+			return nil
+		}
+		return &t
+	}
+	parseSize := func(key string) int {
+		value := query.Get(key)
+		if value == "" || err != nil {
+			return 0
+		}
+		size, perr := strconv.Atoi(value)
+		if perr != nil || size <= 0 || size > maxServerPlotSize {
+			err = fmt.Errorf("%s %q is no size between 1 and %d pixels: %w", key, value, maxServerPlotSize, ErrInvalidValue)
+			return 0
+		}
+		return size
+	}
+	v.startFreq = parseFreq("startFreq")
+	v.endFreq = parseFreq("endFreq")
+	v.startTime = parseTime("startTime")
+	v.endTime = parseTime("endTime")
+	v.width = parseSize("width")
+	v.height = parseSize("height")
+	return v, err
+}
+
+//apply returns a copy of drawer with the view applied to all widgets that support it. The copy shares the data with
+//drawer, but drawing it doesn't change drawer or its widgets
+func (s plotView) apply(drawer *Drawer) *Drawer {
+	builder := *drawer.DrawerBuilder
+	builder.drawable = nil
+	builder.canvas = nil
+	if s.width > 0 {
+		builder.plotWidth = s.width
+	}
+	if s.height > 0 {
+		builder.plotHeight = s.height
+	}
+	builder.plots = make([]DrawerWidget, len(drawer.plots))
+	for i, p := range drawer.plots {
+		if v, ok := p.(interface {
+			withView(drawer *DrawerBuilder, view plotView) DrawerWidget
+		}); ok {
+			p = v.withView(&builder, s)
+		}
+		builder.plots[i] = p
+	}
+	return newDrawer(&builder)
+}
+
+//viewRange returns start and end replaced by the ones of the view that are set, if they still form a range
+func viewRange[T float64 | time.Duration](start T, end T, viewStart *T, viewEnd *T) (T, T) {
+	newStart, newEnd := start, end
+	if viewStart != nil {
+		newStart = *viewStart
+	}
+	if viewEnd != nil {
+		newEnd = *viewEnd
+	}
+	if newStart >= newEnd {
+		return start, end
+	}
+	return newStart, newEnd
+}
+
+//withView returns a copy of the plot drawn with drawer and the time range of view
+func (s *WaveDrawer) withView(drawer *DrawerBuilder, view plotView) DrawerWidget {
+	c := *s
+	c.DrawerBuilder = drawer
+	c.cache = nil
+	c.startTime, c.endTime = viewRange(s.startTime, s.endTime, view.startTime, view.endTime)
+	return &c
+}
+
+//withView returns a copy of the plot drawn with drawer and the frequency range of view
+func (s *SpectrumDrawer) withView(drawer *DrawerBuilder, view plotView) DrawerWidget {
+	c := *s
+	c.DrawerBuilder = drawer
+	c.cache = nil
+	c.startFreq, c.endFreq = viewRange(s.startFreq, s.endFreq, view.startFreq, view.endFreq)
+	return &c
+}
+
+//withView returns a copy of the plot drawn with drawer and the time and frequency range of view
+func (s *SpectrogramDrawer) withView(drawer *DrawerBuilder, view plotView) DrawerWidget {
+	c := *s
+	c.DrawerBuilder = drawer
+	c.cache = nil
+	c.startFreq, c.endFreq = viewRange(s.startFreq, s.endFreq, view.startFreq, view.endFreq)
+	c.startTime, c.endTime = viewRange(s.startTime, s.endTime, view.startTime, view.endTime)
+	return &c
+}
+
+//withView returns a copy of the colorbar drawn with drawer
+func (s *ColorbarDrawer) withView(drawer *DrawerBuilder, _ plotView) DrawerWidget {
+	c := *s
+	c.DrawerBuilder = drawer
+	c.cache = nil
+	return &c
+}
+
+//withView returns a copy of the plot drawn with drawer. The window of the plot always ends at the latest sample, so
+//the time range of view is ignored
+func (s *StreamingWaveDrawer) withView(drawer *DrawerBuilder, _ plotView) DrawerWidget {
+	c := *s
+	c.DrawerBuilder = drawer
+	c.cache = nil
+	c.columns = nil
+	c.drawn = nil
+	return &c
+}
@@ -0,0 +1,131 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+//newServerDrawer creates a drawer with a wave and a spectrum for the server tests
+func newServerDrawer(t *testing.T) (*Drawer, *WaveDrawer, *SpectrumDrawer) {
+	d := NewDrawer().PlotWidth(200).PlotHeight(50).LabelSpace(40)
+	wave := NewWaveDrawer(d, []time.Duration{0, time.Second, 2 * time.Second}, "wave").SetItems(NewWaveDrawerItems([]float64{0, 1, -1}, nil))
+	spectrum := NewSpectrumDrawer(d, []float64{100, 200, 300}, "spectrum").SetItems(NewSpectrumDrawerItems([]float64{1, 0.5, 0.25}, true, nil))
+	drawer, err := d.AddPlot(wave).AddPlot(spectrum).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return drawer, wave, spectrum
+}
+
+func TestServerImage(t *testing.T) {
+	drawer, wave, spectrum := newServerDrawer(t)
+	server := httptest.NewServer(http.StripPrefix("/plots", NewServer().Register("bench", drawer)))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/plots/bench.png?width=300&height=60&startFreq=150&endFreq=250&startTime=500ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	//The bottom margin of the spectrum is widened to fit the notes and the frequency range
+	if b := img.Bounds(); b.Dx() != 380 || b.Dy() != 140+146 {
+		t.Errorf("expected the plots to be resized to 380x286, got %v", b)
+	}
+	if wave.startTime != 0 || spectrum.startFreq != 20 || drawer.GetWidth() != 280 {
+		t.Errorf("expected the registered drawer to be unchanged")
+	}
+
+	res, err = http.Get(server.URL + "/plots/bench.svg?endTime=1s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "image/svg+xml" {
+		t.Errorf("expected a SVG, got %d %s", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+
+	for path, status := range map[string]int{
+		"/plots/bench.png?width=0":        http.StatusBadRequest,
+		"/plots/bench.png?startTime=soon": http.StatusBadRequest,
+		"/plots/other.png":                http.StatusNotFound,
+		//Each size is allowed, but both plots together have more than maxServerPixels
+		"/plots/bench.png?width=10000&height=10000": http.StatusBadRequest,
+	} {
+		res, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != status {
+			t.Errorf("expected %s to return %d, got %d", path, status, res.StatusCode)
+		}
+	}
+}
+
+func TestServerUpdate(t *testing.T) {
+	drawer, wave, _ := newServerDrawer(t)
+	s := NewServer().Register("bench", drawer)
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/bench?format=svg&endFreq=1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := new(strings.Builder)
+	_, _ = bufio.NewReader(res.Body).WriteTo(page)
+	res.Body.Close()
+	for _, want := range []string{`src="./bench.svg?endFreq=1000"`, `EventSource("./bench/events")`} {
+		if !strings.Contains(page.String(), want) {
+			t.Errorf("expected the page to contain %s, got %s", want, page)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/bench/events", nil)
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	events := bufio.NewReader(res.Body)
+	if line, err := events.ReadString('\n'); err != nil || !strings.HasPrefix(line, "retry:") {
+		t.Fatalf("expected the stream to start, got %q %v", line, err)
+	}
+
+	if err := s.Update("bench", func(d *Drawer) {
+		wave.SetItems(NewWaveDrawerItems([]float64{1, 0, 1}, nil))
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		line, err := events.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasPrefix(line, "data:") {
+			if line != "data: 1\n" {
+				t.Errorf("expected the first update, got %q", line)
+			}
+			break
+		}
+	}
+	if len(wave.items) != 2 {
+		t.Errorf("expected the update to be applied")
+	}
+	if err := s.Update("other", nil); !errors.Is(err, ErrUnknownDrawer) {
+		t.Errorf("expected ErrUnknownDrawer, got %v", err)
+	}
+}
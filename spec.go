@@ -0,0 +1,649 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	mn "github.com/michaelhugi/go-hugipipes-musical-notes"
+	"image/color"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//ErrInvalidSpec is returned if a FigureSpec can't be loaded, like an unknown plot type or a missing data reference
+var ErrInvalidSpec = errors.New("invalid spec")
+
+//FigureSpec is a serializable description of a Drawer and its plots. The data of the plots isn't part of the spec,
+//plots reference it by name in a FigureData, so the same configuration can be applied to different measurements.
+//Optional fields that are empty use the defaults of the widgets. Specs are serialized as JSON, see the package
+//documentation for the limits
+type FigureSpec struct {
+	PlotWidth  int `json:"plotWidth,omitempty"`
+	PlotHeight int `json:"plotHeight,omitempty"`
+	LabelSpace int `json:"labelSpace,omitempty"`
+	//A4 is the frequency of A4 in Hz of the equal temperament used for the note axes. Default is 440Hz
+	A4 float64 `json:"a4,omitempty"`
+	//TitleSize, AxisSize and TickSize are the font sizes in pixels, 0 keeps the default
+	TitleSize float64 `json:"titleSize,omitempty"`
+	AxisSize  float64 `json:"axisSize,omitempty"`
+	TickSize  float64 `json:"tickSize,omitempty"`
+	//Theme is one of "dark", "light", "print" or "highContrast". Default is "dark"
+	Theme string     `json:"theme,omitempty"`
+	Plots []PlotSpec `json:"plots"`
+}
+
+//PlotSpec describes one plot of a FigureSpec. Type is one of "wave", "spectrum" or "spectrogram", fields not used by
+//the type are ignored. Colors are hex strings like "#ff0000" or "#ff000080", times are durations like "1.5s"
+type PlotSpec struct {
+	Type  string `json:"type"`
+	Title string `json:"title,omitempty"`
+	//Times references the times in seconds of a wave or the frame start times of a spectrogram
+	Times string `json:"times,omitempty"`
+	//Frequencies references the frequencies in Hz of a spectrum or the bins of a spectrogram
+	Frequencies string `json:"frequencies,omitempty"`
+	//Magnitudes references the matrix of a spectrogram with one row per frame
+	Magnitudes string     `json:"magnitudes,omitempty"`
+	Items      []ItemSpec `json:"items,omitempty"`
+	Marks      []MarkSpec `json:"marks,omitempty"`
+	//Legend is one of "topRight", "topLeft", "bottomLeft", "bottomRight", "outsideRight" or "hidden"
+	Legend string `json:"legend,omitempty"`
+	//Width and Height override the plot size of the figure
+	Width   int          `json:"width,omitempty"`
+	Height  int          `json:"height,omitempty"`
+	Margins *MarginsSpec `json:"margins,omitempty"`
+	//A4 overrides the temperament of the figure
+	A4 float64 `json:"a4,omitempty"`
+	//Theme overrides the theme of the figure, the colors override the theme
+	Theme           string     `json:"theme,omitempty"`
+	BackgroundColor string     `json:"backgroundColor,omitempty"`
+	DividerColor    string     `json:"dividerColor,omitempty"`
+	AxisColor       string     `json:"axisColor,omitempty"`
+	TitleColor      string     `json:"titleColor,omitempty"`
+	StartFreq       *float64   `json:"startFreq,omitempty"`
+	EndFreq         *float64   `json:"endFreq,omitempty"`
+	FreqScale       string     `json:"freqScale,omitempty"`
+	StartTime       string     `json:"startTime,omitempty"`
+	EndTime         string     `json:"endTime,omitempty"`
+	YScale          string     `json:"yScale,omitempty"`
+	YRange          *RangeSpec `json:"yRange,omitempty"`
+	YUnit           string     `json:"yUnit,omitempty"`
+	DecibelFloor    *float64   `json:"decibelFloor,omitempty"`
+	Decimation      string     `json:"decimation,omitempty"`
+	EnvelopeRMS     bool       `json:"envelopeRMS,omitempty"`
+	//Colormap is one of "viridis", "magma", "inferno", "grayscale", "diverging" or "heat". Default is the one of the
+	//theme
+	Colormap        string `json:"colormap,omitempty"`
+	ReverseColormap bool   `json:"reverseColormap,omitempty"`
+	//Colorbar adds a colorbar right of a spectrogram
+	Colorbar bool `json:"colorbar,omitempty"`
+}
+
+//ItemSpec describes the items of a wave or spectrum. Style is one of "dots", "lines" or "smoothLines" for waves, Line
+//draws spectrum items as lines from the bottom instead of points
+type ItemSpec struct {
+	Data  string `json:"data"`
+	Name  string `json:"name,omitempty"`
+	Color string `json:"color,omitempty"`
+	Style string `json:"style,omitempty"`
+	Line  bool   `json:"line,omitempty"`
+}
+
+//MarkSpec describes a highlighted frequency of a spectrum
+type MarkSpec struct {
+	Frequency float64 `json:"frequency"`
+	Name      string  `json:"name,omitempty"`
+	Color     string  `json:"color,omitempty"`
+}
+
+//MarginsSpec contains the space around a plot on every side
+type MarginsSpec struct {
+	Top    int `json:"top"`
+	Right  int `json:"right"`
+	Bottom int `json:"bottom"`
+	Left   int `json:"left"`
+}
+
+//RangeSpec is a range of values shown on an axis
+type RangeSpec struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+//FigureData contains the data referenced by the plots of a FigureSpec. Series are one-dimensional like times,
+//frequencies or points, matrices are two-dimensional like the magnitudes of a spectrogram
+type FigureData struct {
+	Series   map[string][]float64   `json:"series,omitempty"`
+	Matrices map[string][][]float64 `json:"matrices,omitempty"`
+}
+
+//NewFigureData is the constructor for FigureData
+func NewFigureData() *FigureData {
+	return &FigureData{
+		Series:   make(map[string][]float64),
+		Matrices: make(map[string][][]float64),
+	}
+}
+
+//SetSeries adds a one-dimensional series referenced by name
+func (s *FigureData) SetSeries(name string, values []float64) *FigureData {
+	s.Series[name] = values
+	return s
+}
+
+//SetMatrix adds a two-dimensional matrix referenced by name
+func (s *FigureData) SetMatrix(name string, values [][]float64) *FigureData {
+	s.Matrices[name] = values
+	return s
+}
+
+//series returns the series referenced by name
+func (s *FigureData) series(name string) ([]float64, error) {
+	values, ok := s.Series[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown series %q: %w", name, ErrInvalidSpec)
+	}
+	return values, nil
+}
+
+//matrix returns the matrix referenced by name
+func (s *FigureData) matrix(name string) ([][]float64, error) {
+	values, ok := s.Matrices[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown matrix %q: %w", name, ErrInvalidSpec)
+	}
+	return values, nil
+}
+
+//LoadFigureSpec reads the JSON file at path, see ReadFigureSpec
+func LoadFigureSpec(path string) (*FigureSpec, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadFigureSpec(f)
+}
+
+//ReadFigureSpec decodes a FigureSpec from JSON. Unknown fields are rejected, so typos don't silently fall back to
+//defaults
+func ReadFigureSpec(r io.Reader) (*FigureSpec, error) {
+	d := json.NewDecoder(r)
+	d.DisallowUnknownFields()
+	spec := &FigureSpec{}
+	if err := d.Decode(spec); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrInvalidSpec)
+	}
+	return spec, nil
+}
+
+//WriteTo writes the spec as indented JSON to w
+func (s *FigureSpec) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+//Save writes the spec as JSON to the file at path
+func (s *FigureSpec) Save(path string) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	_, err = s.WriteTo(f)
+	return err
+}
+
+//Builder creates a DrawerBuilder with all plots of the spec filled with data. Errors of a plot are returned as
+//*PlotError
+func (s *FigureSpec) Builder(data *FigureData) (*DrawerBuilder, error) {
+	if data == nil {
+		data = NewFigureData()
+	}
+	drawer := NewDrawer()
+	theme, err := specTheme(s.Theme)
+	if err != nil {
+		return nil, err
+	}
+	if theme != nil {
+		drawer.Theme(*theme)
+	}
+	if s.LabelSpace > 0 {
+		drawer.LabelSpace(s.LabelSpace)
+	}
+	if s.PlotWidth > 0 {
+		drawer.PlotWidth(s.PlotWidth)
+	}
+	if s.PlotHeight > 0 {
+		drawer.PlotHeight(s.PlotHeight)
+	}
+	if s.TitleSize > 0 || s.AxisSize > 0 || s.TickSize > 0 {
+		drawer.FontSizes(specSize(s.TitleSize, drawer.titleSize), specSize(s.AxisSize, drawer.axisSize), specSize(s.TickSize, drawer.tickSize))
+	}
+	for i, p := range s.Plots {
+		a4 := s.A4
+		if p.A4 > 0 {
+			a4 = p.A4
+		}
+		if a4 <= 0 {
+			a4 = 440
+		}
+		widget, err := p.widget(drawer, data, mn.NewMTemperamentEqual(a4))
+		if err != nil {
+			return nil, &PlotError{Index: i, Title: p.Title, Err: err}
+		}
+		if sg, ok := widget.(*SpectrogramDrawer); ok && p.Colorbar {
+			drawer.AddRow(sg, NewSpectrogramColorbar(drawer, sg, ""))
+			continue
+		}
+		drawer.AddPlot(widget)
+	}
+	return drawer, nil
+}
+
+//Drawer creates and validates the Drawer described by the spec, see Builder
+func (s *FigureSpec) Drawer(data *FigureData) (*Drawer, error) {
+	drawer, err := s.Builder(data)
+	if err != nil {
+		return nil, err
+	}
+	return drawer.Build()
+}
+
+//widget creates the widget described by the spec
+func (s *PlotSpec) widget(drawer *DrawerBuilder, data *FigureData, temp mn.MTemperament) (DrawerWidget, error) {
+	switch s.Type {
+	case "wave":
+		return s.waveDrawer(drawer, data)
+	case "spectrum":
+		return s.spectrumDrawer(drawer, data, temp)
+	case "spectrogram":
+		return s.spectrogramDrawer(drawer, data, temp)
+	}
+	return nil, fmt.Errorf("unknown plot type %q: %w", s.Type, ErrInvalidSpec)
+}
+
+//waveDrawer creates a WaveDrawer
+func (s *PlotSpec) waveDrawer(drawer *DrawerBuilder, data *FigureData) (*WaveDrawer, error) {
+	seconds, err := data.series(s.Times)
+	if err != nil {
+		return nil, err
+	}
+	times := make([]time.Duration, len(seconds))
+	for i, t := range seconds {
+		times[i] = time.Duration(t * float64(time.Second))
+	}
+	w := NewWaveDrawer(drawer, times, s.Title)
+	colors, err := s.colors()
+	if err != nil {
+		return nil, err
+	}
+	w.BackgroundColor(colors[0]).DividerColor(colors[1]).AxisColor(colors[2]).TitleColor(colors[3])
+	theme, err := specTheme(s.Theme)
+	if err != nil {
+		return nil, err
+	}
+	if theme != nil {
+		w.Theme(*theme)
+	}
+	w.Width(s.Width).Height(s.Height)
+	if m := s.Margins; m != nil {
+		w.Margins(m.Top, m.Right, m.Bottom, m.Left)
+	}
+	for _, item := range s.Items {
+		points, err := data.series(item.Data)
+		if err != nil {
+			return nil, err
+		}
+		c, err := parseSpecColor(item.Color, nil)
+		if err != nil {
+			return nil, err
+		}
+		style, err := parseSpecName(item.Style, "style", map[string]WaveDrawStyle{
+			"dots":        WaveDrawStyleDots,
+			"lines":       WaveDrawStyleLines,
+			"smoothLines": WaveDrawStyleSmoothLines,
+		})
+		if err != nil {
+			return nil, err
+		}
+		w.SetItems(NewWaveDrawerItems(points, c).Style(style).Name(item.Name))
+	}
+	decimation, err := parseSpecName(s.Decimation, "decimation", map[string]WaveDecimation{
+		"auto":   WaveDecimationAuto,
+		"off":    WaveDecimationOff,
+		"always": WaveDecimationAlways,
+	})
+	if err != nil {
+		return nil, err
+	}
+	w.Decimation(decimation).EnvelopeRMS(s.EnvelopeRMS)
+	legend, err := s.legend()
+	if err != nil {
+		return nil, err
+	}
+	w.Legend(legend)
+	if s.YUnit != "" {
+		w.YUnit(s.YUnit)
+	}
+	start, end, err := s.timeRange()
+	if err != nil {
+		return nil, err
+	}
+	//Set the end twice, so a range outside of the default one isn't rejected by the setters
+	if end != nil {
+		w.EndTime(*end)
+	}
+	if start != nil {
+		w.StartTime(*start)
+	}
+	if end != nil {
+		w.EndTime(*end)
+	}
+	return w, nil
+}
+
+//spectrumDrawer creates a SpectrumDrawer
+func (s *PlotSpec) spectrumDrawer(drawer *DrawerBuilder, data *FigureData, temp mn.MTemperament) (*SpectrumDrawer, error) {
+	frequencies, err := data.series(s.Frequencies)
+	if err != nil {
+		return nil, err
+	}
+	sp := NewSpectrumDrawer(drawer, frequencies, s.Title).Temperament(temp)
+	colors, err := s.colors()
+	if err != nil {
+		return nil, err
+	}
+	sp.BackgroundColor(colors[0]).DividerColor(colors[1]).AxisColor(colors[2]).TitleColor(colors[3])
+	theme, err := specTheme(s.Theme)
+	if err != nil {
+		return nil, err
+	}
+	if theme != nil {
+		sp.Theme(*theme)
+	}
+	sp.Width(s.Width).Height(s.Height)
+	if m := s.Margins; m != nil {
+		sp.Margins(m.Top, m.Right, m.Bottom, m.Left)
+	}
+	for _, item := range s.Items {
+		points, err := data.series(item.Data)
+		if err != nil {
+			return nil, err
+		}
+		c, err := parseSpecColor(item.Color, nil)
+		if err != nil {
+			return nil, err
+		}
+		sp.SetItems(NewSpectrumDrawerItems(points, item.Line, c).Name(item.Name))
+	}
+	for _, mark := range s.Marks {
+		c, err := parseSpecColor(mark.Color, yellow)
+		if err != nil {
+			return nil, err
+		}
+		sp.SetMark(NewSpectrumDrawerMark(mark.Frequency, c).Name(mark.Name))
+	}
+	legend, err := s.legend()
+	if err != nil {
+		return nil, err
+	}
+	sp.Legend(legend)
+	freqScale, err := s.freqScale()
+	if err != nil {
+		return nil, err
+	}
+	sp.FreqScale(freqScale)
+	if s.EndFreq != nil {
+		sp.EndFreq(*s.EndFreq)
+	}
+	if s.StartFreq != nil {
+		sp.StartFreq(*s.StartFreq)
+	}
+	if s.EndFreq != nil {
+		sp.EndFreq(*s.EndFreq)
+	}
+	yScale, err := s.yScale()
+	if err != nil {
+		return nil, err
+	}
+	sp.YScale(yScale)
+	if s.YRange != nil {
+		sp.YRange(s.YRange.Min, s.YRange.Max)
+	}
+	if s.DecibelFloor != nil {
+		sp.DecibelFloor(*s.DecibelFloor)
+	}
+	if s.YUnit != "" {
+		sp.YUnit(s.YUnit)
+	}
+	return sp, nil
+}
+
+//spectrogramDrawer creates a SpectrogramDrawer
+func (s *PlotSpec) spectrogramDrawer(drawer *DrawerBuilder, data *FigureData, temp mn.MTemperament) (*SpectrogramDrawer, error) {
+	seconds, err := data.series(s.Times)
+	if err != nil {
+		return nil, err
+	}
+	times := make([]time.Duration, len(seconds))
+	for i, t := range seconds {
+		times[i] = time.Duration(t * float64(time.Second))
+	}
+	frequencies, err := data.series(s.Frequencies)
+	if err != nil {
+		return nil, err
+	}
+	magnitudes, err := data.matrix(s.Magnitudes)
+	if err != nil {
+		return nil, err
+	}
+	sg := NewSpectrogramDrawer(drawer, times, frequencies, magnitudes, s.Title).Temperament(temp)
+	colors, err := s.colors()
+	if err != nil {
+		return nil, err
+	}
+	sg.BackgroundColor(colors[0]).DividerColor(colors[1]).AxisColor(colors[2]).TitleColor(colors[3])
+	theme, err := specTheme(s.Theme)
+	if err != nil {
+		return nil, err
+	}
+	if theme != nil {
+		sg.Theme(*theme)
+	}
+	sg.Width(s.Width).Height(s.Height)
+	if m := s.Margins; m != nil {
+		sg.Margins(m.Top, m.Right, m.Bottom, m.Left)
+	}
+	freqScale, err := s.freqScale()
+	if err != nil {
+		return nil, err
+	}
+	sg.FreqScale(freqScale)
+	if s.EndFreq != nil {
+		sg.EndFreq(*s.EndFreq)
+	}
+	if s.StartFreq != nil {
+		sg.StartFreq(*s.StartFreq)
+	}
+	if s.EndFreq != nil {
+		sg.EndFreq(*s.EndFreq)
+	}
+	start, end, err := s.timeRange()
+	if err != nil {
+		return nil, err
+	}
+	if end != nil {
+		sg.EndTime(*end)
+	}
+	if start != nil {
+		sg.StartTime(*start)
+	}
+	if end != nil {
+		sg.EndTime(*end)
+	}
+	yScale, err := s.yScale()
+	if err != nil {
+		return nil, err
+	}
+	sg.MagnitudeScale(yScale)
+	colormap, err := s.colormap()
+	if err != nil {
+		return nil, err
+	}
+	if colormap != nil {
+		sg.ColorMap(colormap.At)
+	}
+	if s.YRange != nil {
+		sg.MagnitudeRange(s.YRange.Min, s.YRange.Max)
+	}
+	if s.DecibelFloor != nil {
+		sg.DecibelFloor(*s.DecibelFloor)
+	}
+	return sg, nil
+}
+
+//colors returns the background, divider, axis and title colors, nil for the colors of the theme
+func (s *PlotSpec) colors() ([4]color.Color, error) {
+	var colors [4]color.Color
+	var err error
+	for i, c := range []string{s.BackgroundColor, s.DividerColor, s.AxisColor, s.TitleColor} {
+		if colors[i], err = parseSpecColor(c, nil); err != nil {
+			return colors, err
+		}
+	}
+	return colors, nil
+}
+
+//colormap returns the Colormap of the spec, nil if not set
+func (s *PlotSpec) colormap() (*Colormap, error) {
+	if s.Colormap == "" {
+		return nil, nil
+	}
+	colormap, err := parseSpecName(s.Colormap, "colormap", map[string]Colormap{
+		"viridis":   ViridisColormap(),
+		"magma":     MagmaColormap(),
+		"inferno":   InfernoColormap(),
+		"grayscale": GrayscaleColormap(),
+		"diverging": DivergingColormap(),
+		"heat":      HeatColormap(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if s.ReverseColormap {
+		colormap = colormap.Reversed()
+	}
+	return &colormap, nil
+}
+
+//specTheme returns the Theme named name, nil if name is empty
+func specTheme(name string) (*Theme, error) {
+	if name == "" {
+		return nil, nil
+	}
+	theme, err := parseSpecName(name, "theme", map[string]Theme{
+		"dark":         DarkTheme(),
+		"light":        LightTheme(),
+		"print":        PrintTheme(),
+		"highContrast": HighContrastTheme(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &theme, nil
+}
+
+//freqScale returns the FrequencyScale of the spec. Default is FrequencyScaleLinear
+func (s *PlotSpec) freqScale() (FrequencyScale, error) {
+	return parseSpecName(s.FreqScale, "frequency scale", map[string]FrequencyScale{
+		"linear": FrequencyScaleLinear,
+		"log2":   FrequencyScaleLog2,
+		"log10":  FrequencyScaleLog10,
+	})
+}
+
+//yScale returns the YScale of the spec. Default is YScaleLinear
+func (s *PlotSpec) yScale() (YScale, error) {
+	return parseSpecName(s.YScale, "y scale", map[string]YScale{
+		"linear":  YScaleLinear,
+		"shared":  YScaleShared,
+		"fixed":   YScaleFixed,
+		"decibel": YScaleDecibel,
+	})
+}
+
+//legend returns the LegendPosition of the spec. Default is LegendTopRight
+func (s *PlotSpec) legend() (LegendPosition, error) {
+	return parseSpecName(s.Legend, "legend", map[string]LegendPosition{
+		"topRight":     LegendTopRight,
+		"topLeft":      LegendTopLeft,
+		"bottomLeft":   LegendBottomLeft,
+		"bottomRight":  LegendBottomRight,
+		"outsideRight": LegendOutsideRight,
+		"hidden":       LegendHidden,
+	})
+}
+
+//timeRange returns the parsed start and end times, nil if not set
+func (s *PlotSpec) timeRange() (*time.Duration, *time.Duration, error) {
+	var times [2]*time.Duration
+	for i, t := range []string{s.StartTime, s.EndTime} {
+		if t == "" {
+			continue
+		}
+		d, err := time.ParseDuration(t)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%v: %w", err, ErrInvalidSpec)
+		}
+		times[i] = &d
+	}
+	return times[0], times[1], nil
+}
+
+//parseSpecName returns the value named name in values or the zero value if name is empty
+func parseSpecName[T any](name string, kind string, values map[string]T) (T, error) {
+	var value T
+	if name == "" {
+		return value, nil
+	}
+	value, ok := values[name]
+	if !ok {
+		return value, fmt.Errorf("unknown %s %q: %w", kind, name, ErrInvalidSpec)
+	}
+	return value, nil
+}
+
+//specSize returns size or def if size is not set
+func specSize(size float64, def float64) float64 {
+	if size > 0 {
+		return size
+	}
+	return def
+}
+
+//parseSpecColor parses a hex color like "#ff0000" or "#ff000080". An empty string returns def
+func parseSpecColor(hex string, def color.Color) (color.Color, error) {
+	if hex == "" {
+		return def, nil
+	}
+	digits := strings.TrimPrefix(hex, "#")
+	if len(digits) == 6 {
+		digits += "ff"
+	}
+	v, err := strconv.ParseUint(digits, 16, 32)
+	if len(digits) != 8 || err != nil {
+		return nil, fmt.Errorf("color %q: %w", hex, ErrInvalidSpec)
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
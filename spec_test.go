@@ -0,0 +1,155 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"strings"
+	"testing"
+)
+
+const testFigureSpec = `{
+  "plotWidth": 400,
+  "plotHeight": 100,
+  "a4": 442,
+  "tickSize": 10,
+  "theme": "light",
+  "plots": [
+    {
+      "type": "wave",
+      "title": "Wave",
+      "times": "t",
+      "items": [
+        {
+          "data": "left",
+          "color": "#00c800",
+          "style": "lines"
+        }
+      ],
+      "endTime": "2ms"
+    },
+    {
+      "type": "spectrum",
+      "title": "Spectrum",
+      "frequencies": "f",
+      "items": [
+        {
+          "data": "mag",
+          "line": true
+        }
+      ],
+      "marks": [
+        {
+          "frequency": 442,
+          "name": "A4",
+          "color": "#ff000080"
+        }
+      ],
+      "legend": "outsideRight",
+      "startFreq": 50,
+      "endFreq": 1000,
+      "freqScale": "log2",
+      "yScale": "decibel",
+      "decibelFloor": -90
+    },
+    {
+      "type": "spectrogram",
+      "title": "Spectrogram",
+      "times": "frames",
+      "frequencies": "f",
+      "magnitudes": "m",
+      "colormap": "viridis",
+      "reverseColormap": true,
+      "colorbar": true
+    }
+  ]
+}
+`
+
+func newTestFigureData() *FigureData {
+	return NewFigureData().
+		SetSeries("t", []float64{0, 0.001, 0.002, 0.003}).
+		SetSeries("left", []float64{0, 1, 0, -1}).
+		SetSeries("f", []float64{100, 200, 400, 800}).
+		SetSeries("mag", []float64{0.1, 1, 0.5, 0.01}).
+		SetSeries("frames", []float64{0, 0.1}).
+		SetMatrix("m", [][]float64{{0, 1, 0, 0}, {0, 0, 1, 0}})
+}
+
+func TestFigureSpecRoundTrip(t *testing.T) {
+	spec, err := ReadFigureSpec(strings.NewReader(testFigureSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := spec.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != testFigureSpec {
+		t.Errorf("round trip changed the spec:\n%s", buf.String())
+	}
+}
+
+func TestFigureSpecDrawer(t *testing.T) {
+	spec, err := ReadFigureSpec(strings.NewReader(testFigureSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := spec.Drawer(newTestFigureData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.plots) != 4 || d.plotWidth != 400 || d.plotHeight != 100 {
+		t.Fatalf("got %d plots of %dx%d", len(d.plots), d.plotWidth, d.plotHeight)
+	}
+	if d.theme.Background != image.White.C {
+		t.Errorf("expected the light theme, got %+v", d.theme)
+	}
+	if d.titleSize != 16 || d.axisSize != 13 || d.tickSize != 10 {
+		t.Errorf("got font sizes %g, %g and %g", d.titleSize, d.axisSize, d.tickSize)
+	}
+	spectrum := d.plots[1].(*SpectrumDrawer)
+	if spectrum.startFreq != 50 || spectrum.endFreq != 1000 || spectrum.freqScale != FrequencyScaleLog2 || spectrum.decibelFloor != -90 || spectrum.legendPosition != LegendOutsideRight {
+		t.Errorf("spectrum settings not applied: %+v", spectrum)
+	}
+	if a4 := spectrum.temp.Octave(4).Note(9).ExactFrequency(); a4 != 442 {
+		t.Errorf("A4 = %f, want 442", a4)
+	}
+	colorbar := d.plots[3].(*ColorbarDrawer)
+	if colorbar.spectrogram != d.plots[2] || d.cells[3].row != d.cells[2].row {
+		t.Error("expected a colorbar beside the spectrogram")
+	}
+	if c := colorbar.spectrogram.colors.colorMap(0); c != ViridisColormap().At(1) {
+		t.Errorf("expected the reversed viridis colormap, got %v", c)
+	}
+	if _, err := d.Render(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFigureSpecErrors(t *testing.T) {
+	if _, err := ReadFigureSpec(strings.NewReader(`{"plots":[],"plotWidht":3}`)); !errors.Is(err, ErrInvalidSpec) {
+		t.Errorf("unknown field: got %v", err)
+	}
+	specs := []string{
+		`{"plots":[{"type":"pie"}]}`,
+		`{"plots":[{"type":"wave","times":"missing"}]}`,
+		`{"plots":[{"type":"wave","times":"t","items":[{"data":"left","color":"red"}]}]}`,
+		`{"plots":[{"type":"spectrum","frequencies":"f","freqScale":"log3"}]}`,
+		`{"plots":[{"type":"wave","times":"t","endTime":"soon"}]}`,
+		`{"plots":[{"type":"wave","times":"t","legend":"center"}]}`,
+		`{"plots":[{"type":"wave","times":"t","theme":"neon"}]}`,
+		`{"plots":[{"type":"spectrogram","times":"frames","frequencies":"f","magnitudes":"m","colormap":"jet"}]}`,
+	}
+	for _, s := range specs {
+		spec, err := ReadFigureSpec(strings.NewReader(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = spec.Drawer(newTestFigureData())
+		var plotErr *PlotError
+		if !errors.Is(err, ErrInvalidSpec) || !errors.As(err, &plotErr) {
+			t.Errorf("%s: got %v", s, err)
+		}
+	}
+}
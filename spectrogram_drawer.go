@@ -0,0 +1,555 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"context"
+	"fmt"
+	mn "github.com/michaelhugi/go-hugipipes-musical-notes"
+	"image"
+	"image/color"
+	"sort"
+	"time"
+)
+
+//SpectrogramDrawer is a widget that can be used in drawer to draw the magnitudes of a signal over time and frequency.
+//The time is shown on the x-axis, the frequency with musical notes on the y-axis and the magnitude as color
+type SpectrogramDrawer struct {
+	*DrawerBuilder
+	size           widgetSize
+	cache          *spectrogramDrawerCache
+	title          string
+	times          []time.Duration
+	frequencies    []float64
+	magnitudes     [][]float64
+	colors         widgetTheme
+	temp           mn.MTemperament
+	startFreq      float64
+	endFreq        float64
+	freqScale      FrequencyScale
+	startTime      time.Duration
+	endTime        time.Duration
+	magnitudeScale YScale
+	magnitudeMin   float64
+	magnitudeMax   float64
+	decibelFloor   float64
+}
+
+//NewSpectrogramDrawer is the constructor for SpectrogramDrawer
+//times are the start times of all frames
+//frequencies are the center frequencies of all bins
+//magnitudes contains one slice per frame with the magnitude of every bin
+func NewSpectrogramDrawer(drawer *DrawerBuilder, times []time.Duration, frequencies []float64, magnitudes [][]float64, title string) *SpectrogramDrawer {
+	s := &SpectrogramDrawer{
+		DrawerBuilder:  drawer,
+		title:          title,
+		times:          times,
+		frequencies:    frequencies,
+		magnitudes:     magnitudes,
+		temp:           mn.NewMTemperamentEqual(440),
+		startFreq:      20,
+		endFreq:        20000,
+		freqScale:      FrequencyScaleLinear,
+		magnitudeScale: YScaleLinear,
+		magnitudeMin:   0,
+		magnitudeMax:   1,
+		decibelFloor:   defaultDecibelFloor,
+	}
+	if len(times) > 0 {
+		s.startTime = times[0]
+		s.endTime = s.timeEdges()[len(times)]
+	}
+	return s
+}
+
+//spectrogramDrawerCache contains data that is recalculated often during drawing
+type spectrogramDrawerCache struct {
+	ctx              context.Context
+	x                int
+	theme            Theme
+	canvas           VectorDrawable
+	margins          margins
+	plotWidth        int
+	plotHeight       int
+	timeFactor       float64
+	freqFactor       float64
+	scaleStart       float64
+	lowestFreq       float64
+	magnitudeRange   yRange
+	calculatedWidth  int
+	calculatedHeight int
+}
+
+//Temperament sets the temperament of the musical notes for the y-axis. Default is equal at A4=440Hz
+func (s *SpectrogramDrawer) Temperament(temp mn.MTemperament) *SpectrogramDrawer {
+	s.temp = temp
+	return s
+}
+
+//ColorMap sets the function mapping a magnitude, normalized to [0,1], to its color, like ViridisColormap().At.
+//Default is the color map of the theme
+func (s *SpectrogramDrawer) ColorMap(colorMap func(v float64) color.Color) *SpectrogramDrawer {
+	s.colors.colorMap = colorMap
+	return s
+}
+
+//Theme sets the colors of this plot, replacing the theme of the DrawerBuilder. Colors set with the color setters
+//still override the theme
+func (s *SpectrogramDrawer) Theme(theme Theme) *SpectrogramDrawer {
+	s.colors.theme = &theme
+	return s
+}
+
+//BackgroundColor sets the background-color of the plot. Default is the background of the theme
+func (s *SpectrogramDrawer) BackgroundColor(backgroundColor color.Color) *SpectrogramDrawer {
+	s.colors.background = backgroundColor
+	return s
+}
+
+//DividerColor sets the divider-color of the plot. Default is the divider color of the theme
+func (s *SpectrogramDrawer) DividerColor(dividerColor color.Color) *SpectrogramDrawer {
+	s.colors.divider = dividerColor
+	return s
+}
+
+//AxisColor sets the color of the axis. Default is the axis color of the theme
+func (s *SpectrogramDrawer) AxisColor(axisColor color.Color) *SpectrogramDrawer {
+	s.colors.axis = axisColor
+	return s
+}
+
+//TitleColor sets the color of the title. Default is the title color of the theme
+func (s *SpectrogramDrawer) TitleColor(titleColor color.Color) *SpectrogramDrawer {
+	s.colors.title = titleColor
+	return s
+}
+
+//Width sets the width of the plot without margins. Default is the plot width of the DrawerBuilder
+func (s *SpectrogramDrawer) Width(width int) *SpectrogramDrawer {
+	s.size.width = max(width, 0)
+	return s
+}
+
+//Height sets the height of the plot without margins. Default is the plot height of the DrawerBuilder
+func (s *SpectrogramDrawer) Height(height int) *SpectrogramDrawer {
+	s.size.height = max(height, 0)
+	return s
+}
+
+//Margins sets the space around the plot for the title and the axis labels. Default is the space measured to fit the
+//text, but at least the label space of the DrawerBuilder on all sides
+func (s *SpectrogramDrawer) Margins(top int, right int, bottom int, left int) *SpectrogramDrawer {
+	s.size.setMargins(top, right, bottom, left)
+	return s
+}
+
+//StartFreq sets the lowest shown frequency in the plot. Default is 20Hz
+func (s *SpectrogramDrawer) StartFreq(startFreq float64) *SpectrogramDrawer {
+	if startFreq >= s.endFreq {
+		return s
+	}
+	s.startFreq = startFreq
+	return s
+}
+
+//EndFreq sets the highest shown frequency in the plot. Default is 20kHz
+func (s *SpectrogramDrawer) EndFreq(endFreq float64) *SpectrogramDrawer {
+	if s.startFreq >= endFreq {
+		return s
+	}
+	s.endFreq = endFreq
+	return s
+}
+
+//StartNote sets the lowest shown frequency in the plot. Default is 20Hz
+func (s *SpectrogramDrawer) StartNote(note mn.MNote) *SpectrogramDrawer {
+	return s.StartFreq(note.LowerFrequency())
+}
+
+//EndNote sets the highest shown frequency in the plot. Default is 20kHz
+func (s *SpectrogramDrawer) EndNote(note mn.MNote) *SpectrogramDrawer {
+	return s.EndFreq(note.UpperFrequency())
+}
+
+//FreqScale sets the distribution of the frequencies along the y-axis. Default is FrequencyScaleLinear
+func (s *SpectrogramDrawer) FreqScale(freqScale FrequencyScale) *SpectrogramDrawer {
+	s.freqScale = freqScale
+	return s
+}
+
+//StartTime sets the start time for the plot. Default is the time of the first frame
+func (s *SpectrogramDrawer) StartTime(startTime time.Duration) *SpectrogramDrawer {
+	if startTime >= s.endTime {
+		return s
+	}
+	s.startTime = startTime
+	return s
+}
+
+//EndTime sets the highest shown time in the plot. Default is the end of the last frame
+func (s *SpectrogramDrawer) EndTime(endTime time.Duration) *SpectrogramDrawer {
+	if s.startTime >= endTime {
+		return s
+	}
+	s.endTime = endTime
+	return s
+}
+
+//MagnitudeScale sets how the magnitudes are mapped to the colors. YScaleLinear and YScaleShared both use the range
+//of all magnitudes. Default is YScaleLinear
+func (s *SpectrogramDrawer) MagnitudeScale(magnitudeScale YScale) *SpectrogramDrawer {
+	s.magnitudeScale = magnitudeScale
+	return s
+}
+
+//MagnitudeRange sets the range of magnitudes mapped to the colors and switches to YScaleFixed. Default is 0 to 1
+func (s *SpectrogramDrawer) MagnitudeRange(min float64, max float64) *SpectrogramDrawer {
+	if min >= max {
+		return s
+	}
+	s.magnitudeMin = min
+	s.magnitudeMax = max
+	s.magnitudeScale = YScaleFixed
+	return s
+}
+
+//DecibelFloor sets the lowest level in dBFS shown with YScaleDecibel. Default is -120dB
+func (s *SpectrogramDrawer) DecibelFloor(decibelFloor float64) *SpectrogramDrawer {
+	if decibelFloor >= 0 {
+		return s
+	}
+	s.decibelFloor = decibelFloor
+	return s
+}
+
+//newSpectrogramDrawerCache creates a new cache with pre-calculated values for plotting to the region bounds to avoid
+//executing the same operation multiple times
+func (s *SpectrogramDrawer) newSpectrogramDrawerCache(bounds image.Rectangle) *spectrogramDrawerCache {
+	lowestFreq := s.freqScale.lowerBound(s.startFreq)
+	scaleStart := s.freqScale.transform(lowestFreq)
+	m := s.plotMargins()
+	plotWidth := bounds.Dx() - m.left - m.right
+	plotHeight := bounds.Dy() - m.top - m.bottom
+	return &spectrogramDrawerCache{
+		x:                bounds.Min.X,
+		theme:            s.colors.resolve(s.DrawerBuilder),
+		margins:          m,
+		plotWidth:        plotWidth,
+		plotHeight:       plotHeight,
+		timeFactor:       float64(plotWidth) / float64(s.endTime.Nanoseconds()-s.startTime.Nanoseconds()),
+		freqFactor:       float64(plotHeight) / (s.freqScale.transform(s.endFreq) - scaleStart),
+		scaleStart:       scaleStart,
+		lowestFreq:       lowestFreq,
+		calculatedWidth:  bounds.Dx(),
+		calculatedHeight: bounds.Dy(),
+	}
+}
+
+//newMagnitudeRange returns the range of magnitudes mapped to the colors according to the magnitude scale
+func (s *SpectrogramDrawer) newMagnitudeRange() yRange {
+	switch s.magnitudeScale {
+	case YScaleFixed:
+		return newYRange(s.magnitudeMin, s.magnitudeMax)
+	case YScaleDecibel:
+		return newDecibelRange(s.decibelFloor)
+	default:
+		return pointsRange(s.magnitudes...)
+	}
+}
+
+//timeEdges returns the start of every frame and the end of the last frame, which is assumed to be as long as the
+//frame before
+func (s *SpectrogramDrawer) timeEdges() []time.Duration {
+	edges := make([]time.Duration, len(s.times)+1)
+	copy(edges, s.times)
+	last := len(s.times) - 1
+	step := time.Nanosecond
+	if last > 0 {
+		step = s.times[last] - s.times[last-1]
+	}
+	edges[last+1] = s.times[last] + step
+	return edges
+}
+
+//freqEdges returns the borders between all bins. The outer bins are assumed to be as wide as their neighbours
+func (s *SpectrogramDrawer) freqEdges() []float64 {
+	n := len(s.frequencies)
+	edges := make([]float64, n+1)
+	if n == 1 {
+		edges[0] = s.frequencies[0] / 2
+		edges[1] = s.frequencies[0] * 1.5
+		return edges
+	}
+	for i := 1; i < n; i++ {
+		edges[i] = (s.frequencies[i-1] + s.frequencies[i]) / 2
+	}
+	edges[0] = s.frequencies[0] - (edges[1] - s.frequencies[0])
+	edges[n] = s.frequencies[n-1] + (s.frequencies[n-1] - edges[n-1])
+	return edges
+}
+
+//cellSpan returns the indices [first,last) of all cells overlapping [lo,hi). Cell i spans from edges[i] to edges[i+1]
+func cellSpan(edges []float64, lo float64, hi float64) (int, int, bool) {
+	cells := len(edges) - 1
+	if cells < 1 || hi <= edges[0] || lo >= edges[cells] {
+		return 0, 0, false
+	}
+	first := sort.SearchFloat64s(edges, lo)
+	if first > 0 && (first > cells || edges[first] > lo) {
+		first--
+	}
+	last := min(sort.SearchFloat64s(edges, hi), cells)
+	if last <= first {
+		last = first + 1
+	}
+	return first, last, true
+}
+
+//freqToY recalculates a frequency to the y-coordinates according to the frequency scale
+func (s *SpectrogramDrawer) freqToY(freq float64, bottom int) int {
+	if freq < s.cache.lowestFreq || freq > s.endFreq {
+		return -1000
+	}
+	return bottom - int((s.freqScale.transform(freq)-s.cache.scaleStart)*s.cache.freqFactor)
+}
+
+//timeToX recalculates a time to the x-coordinates
+func (s *SpectrogramDrawer) timeToX(t time.Duration) int {
+	if t < s.startTime || t > s.endTime {
+		return -1000
+	}
+	return int(float64((t-s.startTime).Nanoseconds())*s.cache.timeFactor) + s.cache.x + s.cache.margins.left
+}
+
+//drawBackground plots the background
+func (s *SpectrogramDrawer) drawBackground(y int) {
+	top := y
+	bottom := top + s.cache.calculatedHeight
+	s.cache.canvas.FillRect(image.Rect(s.cache.x, top, s.cache.x+s.cache.calculatedWidth, bottom), s.cache.theme.Background)
+}
+
+//drawMagnitudes draws one pixel per time and frequency. If multiple frames or bins fall into one pixel, the
+//highest magnitude is shown
+func (s *SpectrogramDrawer) drawMagnitudes(y int) {
+	if len(s.times) == 0 || len(s.frequencies) == 0 {
+		return
+	}
+	timeEdges := s.timeEdges()
+	timeEdgesF := make([]float64, len(timeEdges))
+	for i, t := range timeEdges {
+		timeEdgesF[i] = float64(t.Nanoseconds())
+	}
+	freqEdges := s.freqEdges()
+	bottom := y + s.cache.margins.top + s.cache.plotHeight
+
+	type span struct {
+		first int
+		last  int
+		ok    bool
+	}
+	rows := make([]span, s.cache.plotHeight)
+	for py := range rows {
+		lo := s.freqScale.inverse(s.cache.scaleStart + float64(py)/s.cache.freqFactor)
+		hi := s.freqScale.inverse(s.cache.scaleStart + float64(py+1)/s.cache.freqFactor)
+		rows[py].first, rows[py].last, rows[py].ok = cellSpan(freqEdges, lo, hi)
+	}
+
+	for px := 0; px < s.cache.plotWidth; px++ {
+		if s.cache.ctx.Err() != nil {
+			return
+		}
+		lo := float64(s.startTime.Nanoseconds()) + float64(px)/s.cache.timeFactor
+		hi := float64(s.startTime.Nanoseconds()) + float64(px+1)/s.cache.timeFactor
+		firstFrame, lastFrame, ok := cellSpan(timeEdgesF, lo, hi)
+		if !ok {
+			continue
+		}
+		x := s.cache.x + s.cache.margins.left + px + 1
+		for py, row := range rows {
+			if !row.ok {
+				continue
+			}
+			peak := 0.0
+			for f := firstFrame; f < lastFrame && f < len(s.magnitudes); f++ {
+				frame := s.magnitudes[f]
+				for b := row.first; b < row.last && b < len(frame); b++ {
+					if v := s.cache.magnitudeRange.fraction(s.cache.magnitudeRange.value(frame[b])); v > peak {
+						peak = v
+					}
+				}
+			}
+			s.cache.canvas.Set(x, bottom-py-1, s.cache.theme.ColorMap(peak))
+		}
+	}
+}
+
+//drawXAxis draws the time axis of the plot
+func (s *SpectrogramDrawer) drawXAxis(y int) {
+	y += s.cache.margins.top + s.cache.plotHeight
+	maxX := s.cache.x + s.cache.calculatedWidth - s.cache.margins.right
+	s.cache.canvas.DrawLine(s.cache.x+s.cache.margins.left-s.spacePart, y, maxX, y, s.cache.theme.Axis)
+	dt := (s.endTime - s.startTime) / 5
+	if dt <= 0 {
+		return
+	}
+	for tt := s.startTime; tt <= s.endTime; tt += dt {
+		s.drawTime(tt, y)
+	}
+}
+
+//drawTime draws a time-label to the x-axis
+func (s *SpectrogramDrawer) drawTime(t time.Duration, lineY int) {
+	x := s.timeToX(t)
+	bottom := lineY + s.spacePart*3
+	s.cache.canvas.DrawLine(x, lineY, x, bottom, s.cache.theme.Axis)
+	label := timeLabel(t)
+	style := s.tickStyle()
+	s.cache.canvas.DrawText(x-style.width(label)/2, bottom+s.spacePart/2+style.ascent(), label, style, s.cache.theme.Axis)
+}
+
+//drawYAxis draws the frequency axis of the plot with the musical notes
+func (s *SpectrogramDrawer) drawYAxis(top int) {
+	top += s.cache.margins.top
+	bottom := top + s.cache.plotHeight
+	x := s.cache.x + s.cache.margins.left
+	s.cache.canvas.DrawLine(x, top-s.spacePart, x, bottom, s.cache.theme.Axis)
+
+	s.drawYAxisOctave(s.temp.Octave(mn.Octave0), bottom)
+	s.drawYAxisOctave(s.temp.Octave(mn.Octave1), bottom)
+	s.drawYAxisOctave(s.temp.Octave(mn.Octave2), bottom)
+	s.drawYAxisOctave(s.temp.Octave(mn.Octave3), bottom)
+	s.drawYAxisOctave(s.temp.Octave(mn.Octave4), bottom)
+	s.drawYAxisOctave(s.temp.Octave(mn.Octave5), bottom)
+	s.drawYAxisOctave(s.temp.Octave(mn.Octave6), bottom)
+	s.drawYAxisOctave(s.temp.Octave(mn.Octave7), bottom)
+	s.drawYAxisOctave(s.temp.Octave(mn.Octave8), bottom)
+	s.drawYAxisOctave(s.temp.Octave(mn.Octave9), bottom)
+}
+
+//drawYAxisOctave draws one musical octave in the y-axis. All notes get a tick, the C of the octave is labeled
+func (s *SpectrogramDrawer) drawYAxisOctave(oct mn.MOctave, bottom int) {
+	x := s.cache.x + s.cache.margins.left
+	for _, note := range oct.AllNotes() {
+		y := s.freqToY(note.ExactFrequency(), bottom)
+		if y < 0 {
+			continue
+		}
+		s.cache.canvas.DrawLine(x-s.spacePart, y, x, y, s.cache.theme.Axis)
+	}
+	c := oct.Note(mn.C)
+	y := s.freqToY(c.ExactFrequency(), bottom)
+	if y < 0 {
+		return
+	}
+	s.cache.canvas.DrawLine(x-3*s.spacePart, y, x, y, s.cache.theme.Axis)
+	label := c.String()
+	style := s.tickStyle()
+	s.cache.canvas.DrawText(x-3*s.spacePart-3-style.width(label), y+(style.ascent()-style.descent())/2, label, style, s.cache.theme.Axis)
+}
+
+//Draw implements DrawerWidget interface
+func (s *SpectrogramDrawer) Draw(region image.Rectangle, canvas VectorDrawable) error {
+	return s.DrawContext(context.Background(), region, canvas)
+}
+
+//DrawContext implements ContextDrawerWidget interface
+func (s *SpectrogramDrawer) DrawContext(ctx context.Context, region image.Rectangle, canvas VectorDrawable) error {
+	y := region.Min.Y
+	s.cache = s.newSpectrogramDrawerCache(region)
+	s.cache.ctx = ctx
+	s.cache.canvas = canvas
+	s.cache.magnitudeRange = s.newMagnitudeRange()
+	s.drawBackground(y)
+	s.drawPlotTitle(s.title, y)
+	s.drawMagnitudes(y)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.drawXAxis(y)
+	s.drawYAxis(y)
+	s.drawDivider(y)
+	return nil
+}
+
+//drawDivider draws a line at the top of the plot if it isn't in the first row and at the left if it isn't in the
+//first column
+func (s *SpectrogramDrawer) drawDivider(y int) {
+	x := s.cache.x
+	if y > 0 {
+		s.cache.canvas.DrawLine(x, y, x+s.cache.calculatedWidth-1, y, s.cache.theme.Divider)
+	}
+	if x > 0 {
+		s.cache.canvas.DrawLine(x, y, x, y+s.cache.calculatedHeight-1, s.cache.theme.Divider)
+	}
+}
+
+//Draws the plot title above the plot or at the top of the widget if the top margin is too small
+func (s *SpectrogramDrawer) drawPlotTitle(title string, top int) {
+	style := s.titleStyle()
+	x := s.cache.x + s.cache.margins.left
+	y := max(top+s.cache.margins.top-s.spacePart-style.descent(), top+style.ascent())
+	s.cache.canvas.DrawText(x, y, title, style, s.cache.theme.Title)
+}
+
+//getTitle returns the title of the plot
+func (s *SpectrogramDrawer) getTitle() string {
+	return s.title
+}
+
+//Validate checks the data of the plot. It is called before the plot is drawn
+func (s *SpectrogramDrawer) Validate() error {
+	if len(s.times) == 0 {
+		return fmt.Errorf("no times: %w", ErrEmptyData)
+	}
+	if len(s.frequencies) == 0 {
+		return fmt.Errorf("no frequencies: %w", ErrEmptyData)
+	}
+	if s.endTime <= s.startTime {
+		return fmt.Errorf("end time %v is not after start time %v: %w", s.endTime, s.startTime, ErrZeroRange)
+	}
+	if s.endFreq <= s.freqScale.lowerBound(s.startFreq) {
+		return fmt.Errorf("end frequency %fHz is too low for the frequency scale: %w", s.endFreq, ErrZeroRange)
+	}
+	if err := validatePoints("frequencies", s.frequencies, len(s.frequencies)); err != nil {
+		return err
+	}
+	//The edges of the cells are interpolated between neighbours, so both axes must be strictly increasing
+	for i := 1; i < len(s.times); i++ {
+		if s.times[i] <= s.times[i-1] {
+			return fmt.Errorf("time %v at %d is not after %v: %w", s.times[i], i, s.times[i-1], ErrInvalidValue)
+		}
+	}
+	for i := 1; i < len(s.frequencies); i++ {
+		if s.frequencies[i] <= s.frequencies[i-1] {
+			return fmt.Errorf("frequency %fHz at %d is not above %fHz: %w", s.frequencies[i], i, s.frequencies[i-1], ErrInvalidValue)
+		}
+	}
+	if len(s.magnitudes) != len(s.times) {
+		return fmt.Errorf("%d frames of magnitudes instead of %d: %w", len(s.magnitudes), len(s.times), ErrLengthMismatch)
+	}
+	for i, frame := range s.magnitudes {
+		if err := validatePoints(fmt.Sprintf("frame %d", i), frame, len(s.frequencies)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Measure implements DrawerWidget interface
+func (s *SpectrogramDrawer) Measure() Measurement {
+	return s.size.measure(s.DrawerBuilder, s.plotMargins())
+}
+
+//plotMargins returns the margins around the plot
+func (s *SpectrogramDrawer) plotMargins() margins {
+	return s.size.plotMargins(s.DrawerBuilder, s.textMargins())
+}
+
+//textMargins returns the margins fitting the title, the time labels and the labels of the notes
+func (s *SpectrogramDrawer) textMargins() margins {
+	m := s.timeAxisMargins(s.startTime, s.endTime)
+	m.top = s.titleMargin(s.title, s.titleStyle())
+	style := s.tickStyle()
+	lowest := s.temp.Octave(mn.Octave0).Note(mn.C).String()
+	highest := s.temp.Octave(mn.Octave9).Note(mn.C).String()
+	m.left = max(m.left, 4*s.spacePart+3+max(style.width(lowest), style.width(highest)))
+	return m
+}
@@ -0,0 +1,130 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"errors"
+	"image/color"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSpectrogramEdges(t *testing.T) {
+	ms := time.Millisecond
+	timeTests := []struct {
+		times []time.Duration
+		want  []time.Duration
+	}{
+		{times: []time.Duration{0, 10 * ms, 20 * ms}, want: []time.Duration{0, 10 * ms, 20 * ms, 30 * ms}},
+		{times: []time.Duration{5 * ms, 7 * ms}, want: []time.Duration{5 * ms, 7 * ms, 9 * ms}},
+		{times: []time.Duration{5 * ms}, want: []time.Duration{5 * ms, 5*ms + time.Nanosecond}},
+	}
+	for _, test := range timeTests {
+		s := &SpectrogramDrawer{times: test.times}
+		if got := s.timeEdges(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("timeEdges of %v = %v, want %v", test.times, got, test.want)
+		}
+	}
+
+	freqTests := []struct {
+		frequencies []float64
+		want        []float64
+	}{
+		{frequencies: []float64{100, 200, 300}, want: []float64{50, 150, 250, 350}},
+		{frequencies: []float64{100, 200, 400}, want: []float64{50, 150, 300, 500}},
+		{frequencies: []float64{100}, want: []float64{50, 150}},
+	}
+	for _, test := range freqTests {
+		s := &SpectrogramDrawer{frequencies: test.frequencies}
+		if got := s.freqEdges(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("freqEdges of %v = %v, want %v", test.frequencies, got, test.want)
+		}
+	}
+}
+
+func TestCellSpan(t *testing.T) {
+	edges := []float64{0, 1, 2, 3}
+	tests := []struct {
+		lo    float64
+		hi    float64
+		first int
+		last  int
+		ok    bool
+	}{
+		{lo: 0.5, hi: 1.5, first: 0, last: 2, ok: true},
+		{lo: 1, hi: 2, first: 1, last: 2, ok: true},
+		{lo: 1.2, hi: 1.3, first: 1, last: 2, ok: true},
+		{lo: -5, hi: 0.5, first: 0, last: 1, ok: true},
+		{lo: 2.9, hi: 10, first: 2, last: 3, ok: true},
+		{lo: -1, hi: 0, ok: false},
+		{lo: 3, hi: 4, ok: false},
+	}
+	for _, test := range tests {
+		first, last, ok := cellSpan(edges, test.lo, test.hi)
+		if ok != test.ok || (ok && (first != test.first || last != test.last)) {
+			t.Errorf("cellSpan(%f, %f) = %d, %d, %t, want %d, %d, %t", test.lo, test.hi, first, last, ok, test.first, test.last, test.ok)
+		}
+	}
+	if _, _, ok := cellSpan([]float64{1}, 0, 2); ok {
+		t.Error("expected no span without cells")
+	}
+}
+
+func TestSpectrogramDrawerColors(t *testing.T) {
+	d := NewDrawer().PlotWidth(100).PlotHeight(100)
+	//Two frames of 10ms and two bins from 50Hz to 150Hz and from 150Hz to 250Hz fill one quarter of the plot each
+	times := []time.Duration{0, 10 * time.Millisecond}
+	magnitudes := [][]float64{{0.25, 1}, {0.5, 0}}
+	sg := NewSpectrogramDrawer(d, times, []float64{100, 200}, magnitudes, "").
+		StartFreq(50).EndFreq(250).MagnitudeRange(0, 1).ColorMap(GrayscaleColormap().At).Margins(10, 10, 10, 10)
+	d.AddPlot(sg)
+	dr, err := d.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := dr.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	//The plot spans from x=11 to x=110 and from y=10 to y=109
+	tests := []struct {
+		x    int
+		y    int
+		want uint8
+	}{
+		{x: 36, y: 84, want: 64},
+		{x: 36, y: 34, want: 255},
+		{x: 86, y: 84, want: 128},
+		{x: 86, y: 34, want: 0},
+	}
+	for _, test := range tests {
+		c := img.At(test.x, test.y).(color.RGBA)
+		if diff := int(c.R) - int(test.want); diff < -1 || diff > 1 || c.R != c.G || c.R != c.B {
+			t.Errorf("expected gray %d at (%d,%d), got %v", test.want, test.x, test.y, c)
+		}
+	}
+}
+
+func TestSpectrogramDrawerValidateOrder(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		times       []time.Duration
+		frequencies []float64
+		err         error
+	}{
+		{times: []time.Duration{0, 10 * ms, 20 * ms}, frequencies: []float64{100, 200}},
+		{times: []time.Duration{0, 20 * ms, 10 * ms}, frequencies: []float64{100, 200}, err: ErrInvalidValue},
+		{times: []time.Duration{0, 10 * ms, 10 * ms}, frequencies: []float64{100, 200}, err: ErrInvalidValue},
+		{times: []time.Duration{0, 10 * ms, 20 * ms}, frequencies: []float64{200, 100}, err: ErrInvalidValue},
+		{times: []time.Duration{0, 10 * ms, 20 * ms}, frequencies: []float64{100, 100}, err: ErrInvalidValue},
+	}
+	for _, test := range tests {
+		magnitudes := make([][]float64, len(test.times))
+		for i := range magnitudes {
+			magnitudes[i] = make([]float64, len(test.frequencies))
+		}
+		sg := NewSpectrogramDrawer(NewDrawer(), test.times, test.frequencies, magnitudes, "").StartTime(0).EndTime(30 * ms)
+		if err := sg.Validate(); !errors.Is(err, test.err) {
+			t.Errorf("Validate with times %v and frequencies %v = %v, want %v", test.times, test.frequencies, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"math/cmplx"
+	"time"
+)
+
+//SpectrumAnalysis calculates the spectrum of raw samples with a windowed FFT, ready to be plotted by SpectrumDrawer
+type SpectrumAnalysis struct {
+	window      Window
+	segmentSize int
+	overlap     float64
+	zeroPadding int
+}
+
+//NewSpectrumAnalysis is the constructor for SpectrumAnalysis. By default the whole signal is analyzed at once with a
+//Hann window and no zero-padding
+func NewSpectrumAnalysis() *SpectrumAnalysis {
+	return &SpectrumAnalysis{
+		window:      WindowHann,
+		segmentSize: 0,
+		overlap:     0.5,
+		zeroPadding: 1,
+	}
+}
+
+//SpectrumAnalysisResult contains the frequencies of all bins with their magnitudes and phases
+type SpectrumAnalysisResult struct {
+	frequencies []float64
+	magnitudes  []float64
+	phases      []float64
+}
+
+//Window sets the window function applied to every segment. Default is WindowHann
+func (s *SpectrumAnalysis) Window(window Window) *SpectrumAnalysis {
+	s.window = window
+	return s
+}
+
+//SegmentSize splits the samples into overlapping segments of segmentSize samples and averages their spectra, which
+//reduces noise at the cost of frequency resolution. Default is 0, which analyzes all samples at once
+func (s *SpectrumAnalysis) SegmentSize(segmentSize int) *SpectrumAnalysis {
+	if segmentSize < 0 {
+		return s
+	}
+	s.segmentSize = segmentSize
+	return s
+}
+
+//Overlap sets the overlap of consecutive segments in [0,1). Default is 0.5
+func (s *SpectrumAnalysis) Overlap(overlap float64) *SpectrumAnalysis {
+	if overlap < 0 || overlap >= 1 {
+		return s
+	}
+	s.overlap = overlap
+	return s
+}
+
+//ZeroPadding extends every segment with zeros to zeroPadding times its length (rounded up to the next power of two)
+//for a finer interpolated spectrum. Default is 1
+func (s *SpectrumAnalysis) ZeroPadding(zeroPadding int) *SpectrumAnalysis {
+	if zeroPadding < 1 {
+		return s
+	}
+	s.zeroPadding = zeroPadding
+	return s
+}
+
+//Analyze calculates the spectrum of samples recorded with sampleRate in Hz. Magnitudes are amplitudes, so a sine with
+//amplitude 1.0 has a magnitude of about 1.0 (0dBFS). If multiple segments are averaged, the magnitudes are the RMS
+//average and the phases are the ones of the averaged complex spectrum
+func (s *SpectrumAnalysis) Analyze(samples []float64, sampleRate float64) (*SpectrumAnalysisResult, error) {
+	segmentSize, hop, err := s.prepare(samples, sampleRate, len(samples))
+	if err != nil {
+		return nil, err
+	}
+	transform := s.newSegmentTransform(segmentSize)
+	power := make([]float64, transform.bins)
+	sum := make([]complex128, transform.bins)
+	segments := 0
+	for start := 0; start+segmentSize <= len(samples); start += hop {
+		for k, v := range transform.transform(samples[start : start+segmentSize]) {
+			a := cmplx.Abs(v)
+			power[k] += a * a
+			sum[k] += v
+		}
+		segments++
+	}
+
+	result := &SpectrumAnalysisResult{
+		frequencies: transform.frequencies(sampleRate),
+		magnitudes:  make([]float64, transform.bins),
+		phases:      make([]float64, transform.bins),
+	}
+	for k := 0; k < transform.bins; k++ {
+		result.magnitudes[k] = math.Sqrt(power[k] / float64(segments))
+		result.phases[k] = cmplx.Phase(sum[k])
+	}
+	return result, nil
+}
+
+//prepare validates the samples and returns the segment size and the distance between consecutive segments. If no
+//segment size is set, defaultSegmentSize is used
+func (s *SpectrumAnalysis) prepare(samples []float64, sampleRate float64, defaultSegmentSize int) (int, int, error) {
+	if len(samples) == 0 {
+		return 0, 0, fmt.Errorf("no samples: %w", ErrEmptyData)
+	}
+	if !(sampleRate > 0) || math.IsInf(sampleRate, 0) {
+		return 0, 0, fmt.Errorf("sample rate %f: %w", sampleRate, ErrInvalidValue)
+	}
+	if err := validatePoints("samples", samples, len(samples)); err != nil {
+		return 0, 0, err
+	}
+	segmentSize := s.segmentSize
+	if segmentSize == 0 {
+		segmentSize = defaultSegmentSize
+	}
+	segmentSize = min(segmentSize, len(samples))
+	//Most periodic windows are zero at the first sample, so the spectrum of a single sample can't be scaled
+	if segmentSize < 2 {
+		return 0, 0, fmt.Errorf("segment of %d samples is too short for a spectrum: %w", segmentSize, ErrEmptyData)
+	}
+	hop := max(1, int(float64(segmentSize)*(1-s.overlap)))
+	return segmentSize, hop, nil
+}
+
+//segmentTransform transforms segments of a fixed size to amplitude-scaled complex spectra
+type segmentTransform struct {
+	window    []float64
+	windowSum float64
+	buf       []complex128
+	bins      int
+}
+
+//newSegmentTransform prepares the window and zero-padded buffer for segments of segmentSize samples
+func (s *SpectrumAnalysis) newSegmentTransform(segmentSize int) *segmentTransform {
+	fftSize := nextPowerOfTwo(segmentSize * s.zeroPadding)
+	t := &segmentTransform{
+		window: s.window.coefficients(segmentSize),
+		buf:    make([]complex128, fftSize),
+		bins:   fftSize/2 + 1,
+	}
+	for _, w := range t.window {
+		t.windowSum += w
+	}
+	return t
+}
+
+//transform returns the spectrum of segment scaled to amplitudes. The result is only valid until the next call
+func (s *segmentTransform) transform(segment []float64) []complex128 {
+	for i := range s.buf {
+		s.buf[i] = 0
+	}
+	for i, w := range s.window {
+		s.buf[i] = complex(segment[i]*w, 0)
+	}
+	fft(s.buf)
+	spectrum := s.buf[:s.bins]
+	for k := range spectrum {
+		spectrum[k] /= complex(s.windowSum, 0)
+		if k != 0 && k != len(s.buf)/2 {
+			spectrum[k] *= 2
+		}
+	}
+	return spectrum
+}
+
+//frequencies returns the center frequencies of all bins
+func (s *segmentTransform) frequencies(sampleRate float64) []float64 {
+	f := make([]float64, s.bins)
+	for k := range f {
+		f[k] = float64(k) * sampleRate / float64(len(s.buf))
+	}
+	return f
+}
+
+//Frequencies returns the center frequencies of all bins in Hz
+func (s *SpectrumAnalysisResult) Frequencies() []float64 {
+	return s.frequencies
+}
+
+//Magnitudes returns the amplitudes of all bins
+func (s *SpectrumAnalysisResult) Magnitudes() []float64 {
+	return s.magnitudes
+}
+
+//Phases returns the phases of all bins in radians between -Pi and Pi
+func (s *SpectrumAnalysisResult) Phases() []float64 {
+	return s.phases
+}
+
+//MagnitudeItems returns the magnitudes as items drawn as lines, ready for SpectrumDrawer.SetItems
+func (s *SpectrumAnalysisResult) MagnitudeItems(color color.Color) *SpectrumDrawerItems {
+	return NewSpectrumDrawerItems(s.magnitudes, true, color)
+}
+
+//PhaseItems returns the phases as items drawn as points, ready for SpectrumDrawer.SetItems
+func (s *SpectrumAnalysisResult) PhaseItems(color color.Color) *SpectrumDrawerItems {
+	return NewSpectrumDrawerItems(s.phases, false, color)
+}
+
+//NewSpectrumDrawerFromSamples is a constructor for SpectrumDrawer calculating the spectrum of samples recorded with
+//sampleRate in Hz. If analysis is nil, NewSpectrumAnalysis is used. No items are set, add the items of the returned
+//result with SetItems
+func NewSpectrumDrawerFromSamples(drawer *DrawerBuilder, samples []float64, sampleRate float64, analysis *SpectrumAnalysis, title string) (*SpectrumDrawer, *SpectrumAnalysisResult, error) {
+	if analysis == nil {
+		analysis = NewSpectrumAnalysis()
+	}
+	result, err := analysis.Analyze(samples, sampleRate)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewSpectrumDrawer(drawer, result.frequencies, title), result, nil
+}
+
+//defaultSpectrogramSegmentSize is the number of samples per frame of a spectrogram if no segment size is set
+const defaultSpectrogramSegmentSize = 2048
+
+//NewSpectrogramDrawerFromSamples is a constructor for SpectrogramDrawer calculating the magnitudes of samples recorded
+//with sampleRate in Hz. Every segment of the analysis becomes one frame, segments are 2048 samples long if no segment
+//size is set. If analysis is nil, NewSpectrumAnalysis is used
+func NewSpectrogramDrawerFromSamples(drawer *DrawerBuilder, samples []float64, sampleRate float64, analysis *SpectrumAnalysis, title string) (*SpectrogramDrawer, error) {
+	if analysis == nil {
+		analysis = NewSpectrumAnalysis()
+	}
+	segmentSize, hop, err := analysis.prepare(samples, sampleRate, defaultSpectrogramSegmentSize)
+	if err != nil {
+		return nil, err
+	}
+	transform := analysis.newSegmentTransform(segmentSize)
+	times := make([]time.Duration, 0)
+	magnitudes := make([][]float64, 0)
+	for start := 0; start+segmentSize <= len(samples); start += hop {
+		frame := make([]float64, transform.bins)
+		for k, v := range transform.transform(samples[start : start+segmentSize]) {
+			frame[k] = cmplx.Abs(v)
+		}
+		times = append(times, time.Duration(float64(start)/sampleRate*float64(time.Second)))
+		magnitudes = append(magnitudes, frame)
+	}
+	return NewSpectrogramDrawer(drawer, times, transform.frequencies(sampleRate), magnitudes, title), nil
+}
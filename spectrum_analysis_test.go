@@ -0,0 +1,115 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+	"testing"
+	"time"
+)
+
+func TestFFT(t *testing.T) {
+	x := make([]complex128, 16)
+	for i := range x {
+		x[i] = complex(math.Sin(float64(i)*0.7)+float64(i%3), 0)
+	}
+	want := make([]complex128, len(x))
+	for k := range want {
+		for n, v := range x {
+			want[k] += v * cmplx.Exp(complex(0, -2*math.Pi*float64(k*n)/float64(len(x))))
+		}
+	}
+	fft(x)
+	for k := range x {
+		if cmplx.Abs(x[k]-want[k]) > 1e-9 {
+			t.Errorf("bin %d: expected %v, got %v", k, want[k], x[k])
+		}
+	}
+}
+
+func TestSpectrumAnalysisAmplitude(t *testing.T) {
+	sampleRate := 8000.0
+	samples := make([]float64, 4096)
+	for i := range samples {
+		samples[i] = 0.5 * math.Sin(2*math.Pi*1000*float64(i)/sampleRate)
+	}
+	for _, w := range []Window{WindowRectangular, WindowHann, WindowBlackmanHarris, WindowFlatTop} {
+		result, err := NewSpectrumAnalysis().Window(w).SegmentSize(1024).ZeroPadding(2).Analyze(samples, sampleRate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Frequencies()) != 1025 {
+			t.Fatalf("expected 1025 bins, got %d", len(result.Frequencies()))
+		}
+		peak := 0
+		for k, m := range result.Magnitudes() {
+			if m > result.Magnitudes()[peak] {
+				peak = k
+			}
+		}
+		if f := result.Frequencies()[peak]; f != 1000 {
+			t.Errorf("window %d: expected peak at 1000Hz, got %fHz", w, f)
+		}
+		if m := result.Magnitudes()[peak]; math.Abs(m-0.5) > 0.01 {
+			t.Errorf("window %d: expected amplitude 0.5, got %f", w, m)
+		}
+	}
+	if _, err := NewSpectrumAnalysis().Analyze(nil, sampleRate); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("expected ErrEmptyData, got %v", err)
+	}
+	if _, err := NewSpectrumAnalysis().Analyze([]float64{0.5}, sampleRate); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("expected ErrEmptyData for a single sample, got %v", err)
+	}
+	if _, err := NewSpectrumAnalysis().SegmentSize(1).Analyze(samples, sampleRate); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("expected ErrEmptyData for segments of a single sample, got %v", err)
+	}
+}
+
+func TestSpectrogramDrawerFromSamples(t *testing.T) {
+	sampleRate := 8000.0
+	samples := make([]float64, 4096)
+	for i := range samples {
+		samples[i] = 0.5 * math.Sin(2*math.Pi*1000*float64(i)/sampleRate)
+	}
+	analysis := NewSpectrumAnalysis().SegmentSize(1024).Overlap(0.5)
+	sg, err := NewSpectrogramDrawerFromSamples(NewDrawer(), samples, sampleRate, analysis, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	//Segments of 1024 samples start every 512 samples, which is every 64ms
+	if len(sg.times) != 7 || sg.times[1] != 64*time.Millisecond || sg.times[6] != 384*time.Millisecond {
+		t.Errorf("expected 7 frames every 64ms, got %v", sg.times)
+	}
+	if len(sg.frequencies) != 513 || sg.frequencies[1] != 7.8125 {
+		t.Fatalf("expected 513 bins of 7.8125Hz, got %d bins", len(sg.frequencies))
+	}
+	for i, frame := range sg.magnitudes {
+		peak := 0
+		for k, m := range frame {
+			if m > frame[peak] {
+				peak = k
+			}
+		}
+		if f := sg.frequencies[peak]; f != 1000 {
+			t.Errorf("frame %d: expected peak at 1000Hz, got %fHz", i, f)
+		}
+		if m := frame[peak]; math.Abs(m-0.5) > 0.01 {
+			t.Errorf("frame %d: expected amplitude 0.5, got %f", i, m)
+		}
+	}
+	if err := sg.Validate(); err != nil {
+		t.Errorf("expected valid data, got %v", err)
+	}
+
+	//Without an analysis the segments are 2048 samples long
+	sg, err = NewSpectrogramDrawerFromSamples(NewDrawer(), samples, sampleRate, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sg.times) != 3 || len(sg.frequencies) != 1025 {
+		t.Errorf("expected 3 frames of 1025 bins, got %d frames of %d bins", len(sg.times), len(sg.frequencies))
+	}
+	if _, err := NewSpectrogramDrawerFromSamples(NewDrawer(), nil, sampleRate, nil, ""); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("expected ErrEmptyData, got %v", err)
+	}
+}
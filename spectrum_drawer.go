@@ -1,11 +1,11 @@
 package go_hugipipes_signal_drawer
 
 import (
+	"context"
 	"fmt"
 	mn "github.com/michaelhugi/go-hugipipes-musical-notes"
 	"image"
 	"image/color"
-	"math"
 	"strings"
 )
 
@@ -13,6 +13,7 @@ import (
 type SpectrumDrawerMark struct {
 	frequency float64
 	color     color.Color
+	name      string
 }
 
 //NewSpectrumDrawerMark is the constructor for SpectrumDrawerMark
@@ -23,64 +24,94 @@ func NewSpectrumDrawerMark(frequency float64, color color.Color) *SpectrumDrawer
 	}
 }
 
+//Name sets the name of the mark shown in the legend. Default is no name, which hides the mark in the legend
+func (s *SpectrumDrawerMark) Name(name string) *SpectrumDrawerMark {
+	s.name = name
+	return s
+}
+
 //SpectrumDrawerItems contains a list of plot points to draw in the spectrum. Multiple items can be plotted to one
 //spectrum (like amplitude and phase)
 type SpectrumDrawerItems struct {
 	points   []float64
 	drawLine bool
 	color    color.Color
+	name     string
 }
 
 //NewSpectrumDrawerItems is the constructor for SpectrumDrawerItems
-//points are all the data-points. It will be automatically scaled to the plot
+//points are all the data-points. It will be scaled to the plot according to the YScale of the SpectrumDrawer
 //drawLine says, if the items should be plotted as lines from the bottom of the plot (amplitudes) or single points (phases)
-//color is the color the plot should have
+//color is the color the plot should have, nil uses the palette of the theme
 func NewSpectrumDrawerItems(points []float64, drawLine bool, color color.Color) *SpectrumDrawerItems {
 	return &SpectrumDrawerItems{
-		points,
-		drawLine,
-		color,
+		points:   points,
+		drawLine: drawLine,
+		color:    color,
 	}
 }
 
+//Name sets the name of the items shown in the legend. Default is no name, which hides the items in the legend
+func (s *SpectrumDrawerItems) Name(name string) *SpectrumDrawerItems {
+	s.name = name
+	return s
+}
+
 //SpectrumDrawer is a widget that can be used in drawer to draw a Frequency-Spectrum
 type SpectrumDrawer struct {
 	*DrawerBuilder
-	cache           *spectrumDrawerCache
-	title           string
-	frequencies     []float64
-	items           []SpectrumDrawerItems
-	marks           []SpectrumDrawerMark
-	backgroundColor color.Color
-	dividerColor    color.Color
-	axisColor       color.Color
-	titleColor      color.Color
-	temp            mn.MTemperament
-	startFreq       float64
-	endFreq         float64
+	size           widgetSize
+	cache          *spectrumDrawerCache
+	title          string
+	frequencies    []float64
+	items          []SpectrumDrawerItems
+	marks          []SpectrumDrawerMark
+	colors         widgetTheme
+	temp           mn.MTemperament
+	startFreq      float64
+	endFreq        float64
+	freqScale      FrequencyScale
+	yScale         YScale
+	yMin           float64
+	yMax           float64
+	decibelFloor   float64
+	yUnit          string
+	yFormatter     func(value float64) string
+	legendPosition LegendPosition
 }
 
 //NewSpectrumDrawer is the constructor for SpectrumDrawer
 func NewSpectrumDrawer(drawer *DrawerBuilder, frequencies []float64, title string) *SpectrumDrawer {
 	return &SpectrumDrawer{
-		DrawerBuilder:   drawer,
-		title:           title,
-		frequencies:     frequencies,
-		backgroundColor: image.Black.C,
-		axisColor:       image.White.C,
-		titleColor:      image.White.C,
-		items:           make([]SpectrumDrawerItems, 0),
-		marks:           make([]SpectrumDrawerMark, 0),
-		dividerColor:    gray,
-		temp:            mn.NewMTemperamentEqual(440),
-		startFreq:       20,
-		endFreq:         20000,
+		DrawerBuilder: drawer,
+		title:         title,
+		frequencies:   frequencies,
+		items:         make([]SpectrumDrawerItems, 0),
+		marks:         make([]SpectrumDrawerMark, 0),
+		temp:          mn.NewMTemperamentEqual(440),
+		startFreq:     20,
+		endFreq:       20000,
+		freqScale:     FrequencyScaleLinear,
+		yScale:        YScaleLinear,
+		yMin:          0,
+		yMax:          1,
+		decibelFloor:  defaultDecibelFloor,
 	}
 }
 
 //spectrumDrawerCache contains data that is recalculated often during drawing
 type spectrumDrawerCache struct {
+	ctx              context.Context
+	x                int
+	theme            Theme
+	canvas           VectorDrawable
+	margins          margins
+	plotWidth        int
+	plotHeight       int
 	freqFactor       float64
+	scaleStart       float64
+	lowestFreq       float64
+	sharedRange      yRange
 	calculatedWidth  int
 	calculatedHeight int
 }
@@ -103,27 +134,59 @@ func (s *SpectrumDrawer) SetMark(mark *SpectrumDrawerMark) *SpectrumDrawer {
 	return s
 }
 
-//BackgroundColor sets the background-color of the plot. Default is black
+//Theme sets the colors of this plot, replacing the theme of the DrawerBuilder. Colors set with the color setters
+//still override the theme
+func (s *SpectrumDrawer) Theme(theme Theme) *SpectrumDrawer {
+	s.colors.theme = &theme
+	return s
+}
+
+//BackgroundColor sets the background-color of the plot. Default is the background of the theme
 func (s *SpectrumDrawer) BackgroundColor(backgroundColor color.Color) *SpectrumDrawer {
-	s.backgroundColor = backgroundColor
+	s.colors.background = backgroundColor
 	return s
 }
 
-//DividerColor sets the divider-color of the plot. Default is gray
+//DividerColor sets the divider-color of the plot. Default is the divider color of the theme
 func (s *SpectrumDrawer) DividerColor(dividerColor color.Color) *SpectrumDrawer {
-	s.dividerColor = dividerColor
+	s.colors.divider = dividerColor
 	return s
 }
 
-//AxisColor sets the color of the axis. Default is white
+//AxisColor sets the color of the axis. Default is the axis color of the theme
 func (s *SpectrumDrawer) AxisColor(axisColor color.Color) *SpectrumDrawer {
-	s.axisColor = axisColor
+	s.colors.axis = axisColor
 	return s
 }
 
-//TitleColor sets the color of the title of the Spectrum.
+//TitleColor sets the color of the title. Default is the title color of the theme
 func (s *SpectrumDrawer) TitleColor(titleColor color.Color) *SpectrumDrawer {
-	s.titleColor = titleColor
+	s.colors.title = titleColor
+	return s
+}
+
+//Width sets the width of the plot without margins. Default is the plot width of the DrawerBuilder
+func (s *SpectrumDrawer) Width(width int) *SpectrumDrawer {
+	s.size.width = max(width, 0)
+	return s
+}
+
+//Height sets the height of the plot without margins. Default is the plot height of the DrawerBuilder
+func (s *SpectrumDrawer) Height(height int) *SpectrumDrawer {
+	s.size.height = max(height, 0)
+	return s
+}
+
+//Margins sets the space around the plot for the title and the axis labels. Default is the space measured to fit the
+//text, but at least the label space of the DrawerBuilder on all sides
+func (s *SpectrumDrawer) Margins(top int, right int, bottom int, left int) *SpectrumDrawer {
+	s.size.setMargins(top, right, bottom, left)
+	return s
+}
+
+//Legend sets where the legend with the names of the items and marks is drawn. Default is LegendTopRight
+func (s *SpectrumDrawer) Legend(position LegendPosition) *SpectrumDrawer {
+	s.legendPosition = position
 	return s
 }
 
@@ -145,6 +208,50 @@ func (s *SpectrumDrawer) EndFreq(endFreq float64) *SpectrumDrawer {
 	return s
 }
 
+//FreqScale sets the distribution of the frequencies along the x-axis. Default is FrequencyScaleLinear
+func (s *SpectrumDrawer) FreqScale(freqScale FrequencyScale) *SpectrumDrawer {
+	s.freqScale = freqScale
+	return s
+}
+
+//YScale sets how the values of the items are mapped to the y-axis. Default is YScaleLinear
+func (s *SpectrumDrawer) YScale(yScale YScale) *SpectrumDrawer {
+	s.yScale = yScale
+	return s
+}
+
+//YRange sets the range shown on the y-axis and switches to YScaleFixed. Default is 0 to 1
+func (s *SpectrumDrawer) YRange(min float64, max float64) *SpectrumDrawer {
+	if min >= max {
+		return s
+	}
+	s.yMin = min
+	s.yMax = max
+	s.yScale = YScaleFixed
+	return s
+}
+
+//DecibelFloor sets the lowest level in dBFS shown with YScaleDecibel. Default is -120dB
+func (s *SpectrumDrawer) DecibelFloor(decibelFloor float64) *SpectrumDrawer {
+	if decibelFloor >= 0 {
+		return s
+	}
+	s.decibelFloor = decibelFloor
+	return s
+}
+
+//YUnit sets the unit appended to the value labels of the y-axis. Default is no unit or dB with YScaleDecibel
+func (s *SpectrumDrawer) YUnit(yUnit string) *SpectrumDrawer {
+	s.yUnit = yUnit
+	return s
+}
+
+//YFormatter sets a function formatting the value labels of the y-axis. It replaces the default formatting with YUnit
+func (s *SpectrumDrawer) YFormatter(yFormatter func(value float64) string) *SpectrumDrawer {
+	s.yFormatter = yFormatter
+	return s
+}
+
 //StartNote sets the lowest shown frequency in the plot. Default is 20Hz
 func (s *SpectrumDrawer) StartNote(note mn.MNote) *SpectrumDrawer {
 	return s.StartFreq(note.LowerFrequency())
@@ -156,41 +263,69 @@ func (s *SpectrumDrawer) EndNote(note mn.MNote) *SpectrumDrawer {
 }
 
 //newSpectrumDrawerCache creates a new cache with pre-calculated values for plotting to avoid executing the same operation multiple times
-func (s *SpectrumDrawer) newSpectrumDrawerCache() *spectrumDrawerCache {
+func (s *SpectrumDrawer) newSpectrumDrawerCache(bounds image.Rectangle) *spectrumDrawerCache {
+	lowestFreq := s.freqScale.lowerBound(s.startFreq)
+	scaleStart := s.freqScale.transform(lowestFreq)
+	m := s.plotMargins()
+	plotWidth := bounds.Dx() - m.left - m.right
 	return &spectrumDrawerCache{
-		freqFactor:       float64(s.plotWidth) / (s.endFreq - s.startFreq),
-		calculatedWidth:  s.plotWidth + 2*s.labelSpace,
-		calculatedHeight: s.plotHeight + 2*s.labelSpace,
+		x:                bounds.Min.X,
+		theme:            s.colors.resolve(s.DrawerBuilder),
+		margins:          m,
+		plotWidth:        plotWidth,
+		plotHeight:       bounds.Dy() - m.top - m.bottom,
+		freqFactor:       float64(plotWidth) / (s.freqScale.transform(s.endFreq) - scaleStart),
+		scaleStart:       scaleStart,
+		lowestFreq:       lowestFreq,
+		calculatedWidth:  bounds.Dx(),
+		calculatedHeight: bounds.Dy(),
+	}
+}
+
+//newSharedRange calculates the common range of all items used with YScaleShared
+func (s *SpectrumDrawer) newSharedRange() yRange {
+	points := make([][]float64, len(s.items))
+	for i, item := range s.items {
+		points[i] = item.points
+	}
+	return pointsRange(points...)
+}
+
+//itemRange returns the range the points of item are scaled to according to the y-scale
+func (s *SpectrumDrawer) itemRange(item SpectrumDrawerItems) yRange {
+	switch s.yScale {
+	case YScaleShared:
+		return s.cache.sharedRange
+	case YScaleFixed:
+		return newYRange(s.yMin, s.yMax)
+	case YScaleDecibel:
+		return newDecibelRange(s.decibelFloor)
+	default:
+		return pointsRange(item.points)
 	}
 }
 
-//freqToX recalculates a frequency to the x-coordinates
+//freqToX recalculates a frequency to the x-coordinates according to the frequency scale
 func (s *SpectrumDrawer) freqToX(freq float64) int {
-	if freq < s.startFreq || freq > s.endFreq {
+	if freq < s.cache.lowestFreq || freq > s.endFreq {
 		return -1000
 	}
 
-	return int((freq-s.startFreq)*s.cache.freqFactor) + s.labelSpace
+	return int((s.freqScale.transform(freq)-s.cache.scaleStart)*s.cache.freqFactor) + s.cache.x + s.cache.margins.left
 }
 
 //drawBackground plots the background
 func (s *SpectrumDrawer) drawBackground(y int) {
 	top := y
 	bottom := top + s.cache.calculatedHeight
-	for x := 0; x <= s.cache.calculatedWidth; x++ {
-		for y := top; y <= bottom; y++ {
-			s.drawable.Set(x, y, s.backgroundColor)
-		}
-	}
+	s.cache.canvas.FillRect(image.Rect(s.cache.x, top, s.cache.x+s.cache.calculatedWidth, bottom), s.cache.theme.Background)
 }
 
 //drawXAxis draws the x-axis of the plot
 func (s *SpectrumDrawer) drawXAxis(y int) {
-	y += s.labelSpace + s.plotHeight
-	maxX := s.cache.calculatedWidth - s.labelSpace
-	for x := s.labelSpace - s.spacePart; x <= maxX; x++ {
-		s.drawable.Set(x, y, s.axisColor)
-	}
+	y += s.cache.margins.top + s.cache.plotHeight
+	maxX := s.cache.x + s.cache.calculatedWidth - s.cache.margins.right
+	s.cache.canvas.DrawLine(s.cache.x+s.cache.margins.left-s.spacePart, y, maxX, y, s.cache.theme.Axis)
 
 	//s.drawXAxisOctave(s.temp.Octave(mn.OctaveMinus1), y)
 	s.drawXAxisOctave(s.temp.Octave(mn.Octave0), y)
@@ -206,21 +341,35 @@ func (s *SpectrumDrawer) drawXAxis(y int) {
 	s.drawStartAndEndFreq(y)
 }
 
+//drawDecades draws a vertical grid line and label at every decade if the frequency scale is FrequencyScaleLog10
+func (s *SpectrumDrawer) drawDecades(y int) {
+	if s.freqScale != FrequencyScaleLog10 {
+		return
+	}
+	top := y + s.cache.margins.top
+	bottom := top + s.cache.plotHeight
+	for _, f := range decades(s.cache.lowestFreq, s.endFreq) {
+		x := s.freqToX(f)
+		s.cache.canvas.DrawLine(x, top, x, bottom, s.cache.theme.Divider)
+		style := s.tickStyle()
+		s.cache.canvas.DrawText(x+3, top+s.spacePart/2+style.ascent(), formatFrequency(f), style, s.cache.theme.Divider)
+	}
+}
+
 //drawStartAndEndFreq draws the frequencies in the x-axis of the lowest and highest frequencies
 func (s *SpectrumDrawer) drawStartAndEndFreq(lineTop int) {
 	lineBottom := lineTop + 5*s.spacePart
-	x1 := s.freqToX(s.startFreq)
-	yFreq := lineTop + s.spacePart*7
+	x1 := s.freqToX(s.cache.lowestFreq)
 	x2 := s.freqToX(s.endFreq)
 
-	for y := lineTop; y <= lineBottom; y++ {
-		s.drawable.Set(x1, y, s.axisColor)
-		s.drawable.Set(x2, y, s.axisColor)
-	}
-	x1 = x1 + 5
-	x2 = x2 - 100
-	s.drawable.DrawString(x1, yFreq, fmt.Sprintf("%fHz", s.startFreq), s.axisColor)
-	s.drawable.DrawString(x2, yFreq, fmt.Sprintf("%fHz", s.endFreq), s.axisColor)
+	s.cache.canvas.DrawLine(x1, lineTop, x1, lineBottom, s.cache.theme.Axis)
+	s.cache.canvas.DrawLine(x2, lineTop, x2, lineBottom, s.cache.theme.Axis)
+	style := s.axisStyle()
+	yFreq := lineBottom + style.ascent()
+	low := fmt.Sprintf("%fHz", s.cache.lowestFreq)
+	high := fmt.Sprintf("%fHz", s.endFreq)
+	s.cache.canvas.DrawText(x1+5, yFreq, low, style, s.cache.theme.Axis)
+	s.cache.canvas.DrawText(x2-5-style.width(high), yFreq, high, style, s.cache.theme.Axis)
 
 }
 
@@ -233,81 +382,118 @@ func (s *SpectrumDrawer) drawXAxisOctave(oct mn.MOctave, lineTop int) {
 	}
 	x2 := s.freqToX(oct.Note(mn.C).ExactFrequency() * 2)
 	lineBottom := lineTop + 4*s.spacePart
-	for y := lineTop; y <= lineBottom; y++ {
-		s.drawable.Set(x1, y, s.axisColor)
-		s.drawable.Set(x2, y, s.axisColor)
-	}
+	s.cache.canvas.DrawLine(x1, lineTop, x1, lineBottom, s.cache.theme.Axis)
+	s.cache.canvas.DrawLine(x2, lineTop, x2, lineBottom, s.cache.theme.Axis)
 	notes := oct.AllNotes()
 	for _, note := range notes {
 		s.drawXAxisNote(note, lineTop)
 	}
 }
 
-//draw draws all content to the drawable
-func (s *SpectrumDrawer) draw(y int) {
-	s.cache = s.newSpectrumDrawerCache()
+//Draw implements DrawerWidget interface
+func (s *SpectrumDrawer) Draw(region image.Rectangle, canvas VectorDrawable) error {
+	return s.DrawContext(context.Background(), region, canvas)
+}
+
+//DrawContext implements ContextDrawerWidget interface
+func (s *SpectrumDrawer) DrawContext(ctx context.Context, region image.Rectangle, canvas VectorDrawable) error {
+	y := region.Min.Y
+	s.cache = s.newSpectrumDrawerCache(region)
+	s.cache.ctx = ctx
+	s.cache.canvas = canvas
+	s.cache.sharedRange = s.newSharedRange()
 	s.drawBackground(y)
-	s.drawPlotTitle(s.title, s.spacePart*3+y)
+	s.drawPlotTitle(s.title, y)
+	s.drawDecades(y)
 	for _, mark := range s.marks {
 		s.drawMark(mark, y)
 	}
-	for _, item := range s.items {
+	for i, item := range s.items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		item.color = itemColor(item.color, s.cache.theme, i)
 		s.drawItem(item, y)
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	s.drawXAxis(y)
 	s.drawYAxis(y)
-	if y > 0 {
-		s.drawDivider(y)
-	}
+	s.legend().draw(s.cache.canvas, s.plotBounds(y))
+	s.drawDivider(y)
+	return nil
+}
+
+//plotBounds returns the region of the plot without margins
+func (s *SpectrumDrawer) plotBounds(y int) image.Rectangle {
+	x := s.cache.x + s.cache.margins.left
+	y += s.cache.margins.top
+	return image.Rect(x, y, x+s.cache.plotWidth, y+s.cache.plotHeight)
+}
 
+//legend returns the legend of all named items and marks
+func (s *SpectrumDrawer) legend() legend {
+	theme := s.colors.resolve(s.DrawerBuilder)
+	entries := make([]legendEntry, 0)
+	for i, item := range s.items {
+		if item.name != "" {
+			entries = append(entries, legendEntry{name: item.name, color: itemColor(item.color, theme, i)})
+		}
+	}
+	for _, mark := range s.marks {
+		if mark.name != "" {
+			entries = append(entries, legendEntry{name: mark.name, color: mark.color})
+		}
+	}
+	return legend{
+		position:   s.legendPosition,
+		entries:    entries,
+		style:      s.tickStyle(),
+		padding:    s.spacePart,
+		background: theme.Background,
+		border:     theme.Divider,
+		textColor:  theme.Axis,
+	}
 }
 
 //drawMark draws a line to highlight a special frequency
 func (s *SpectrumDrawer) drawMark(mark SpectrumDrawerMark, y int) {
 	x := s.freqToX(mark.frequency)
-	bottom := y + s.plotHeight + s.labelSpace
-	top := y + s.labelSpace
-	for y := top; y <= bottom; y++ {
-		s.drawable.Set(x, y, mark.color)
-	}
+	bottom := y + s.cache.plotHeight + s.cache.margins.top
+	top := y + s.cache.margins.top
+	s.cache.canvas.DrawLine(x, top, x, bottom, mark.color)
 }
 
 //drawItem draws the plot-points of a points set to the spectrum
 func (s *SpectrumDrawer) drawItem(item SpectrumDrawerItems, y int) {
-	maxValue := item.points[0]
-	minValue := item.points[0]
-	for _, v := range item.points {
-		maxValue = math.Max(maxValue, v)
-		minValue = math.Min(minValue, v)
-	}
-	offset := -minValue
-	maxValue += offset
-
-	factor := float64(s.plotHeight) / maxValue
-
-	bottom := y + s.labelSpace + s.plotHeight
+	yRange := s.itemRange(item)
+	bottom := y + s.cache.margins.top + s.cache.plotHeight
 	for i, f := range s.frequencies {
-		it := item.points[i]
+		if i%cancelCheckInterval == 0 && s.cache.ctx.Err() != nil {
+			return
+		}
 		x := s.freqToX(f)
 		if x > 0 {
-			yPoint := it + offset
-			yPoint = yPoint * factor
-			YPoint := bottom - int(yPoint)
+			YPoint := yRange.toY(item.points[i], bottom, s.cache.plotHeight)
 			if item.drawLine {
-				for y := bottom; y >= YPoint; y-- {
-					s.drawable.Set(x, y, item.color)
-				}
+				s.cache.canvas.DrawLine(x, bottom, x, YPoint, item.color)
 			} else {
-				s.drawable.Set(x, YPoint, item.color)
+				s.cache.canvas.Set(x, YPoint, item.color)
 			}
 		}
 	}
 }
 
-//drawDivider draws a horizontal line a the end of the plot
+//drawDivider draws a line at the top of the plot if it isn't in the first row and at the left if it isn't in the
+//first column
 func (s *SpectrumDrawer) drawDivider(y int) {
-	for x := 0; x <= s.cache.calculatedWidth; x++ {
-		s.drawable.Set(x, y, s.dividerColor)
+	x := s.cache.x
+	if y > 0 {
+		s.cache.canvas.DrawLine(x, y, x+s.cache.calculatedWidth-1, y, s.cache.theme.Divider)
+	}
+	if x > 0 {
+		s.cache.canvas.DrawLine(x, y, x, y+s.cache.calculatedHeight-1, s.cache.theme.Divider)
 	}
 }
 
@@ -315,46 +501,122 @@ func (s *SpectrumDrawer) drawDivider(y int) {
 func (s *SpectrumDrawer) drawXAxisNote(n mn.MNote, lineTop int) {
 	x1 := s.freqToX(n.ExactFrequency())
 	lineBottom := lineTop + s.spacePart
-	for y := lineTop; y <= lineBottom; y++ {
-		s.drawable.Set(x1, y, s.axisColor)
-	}
-	y := lineBottom + s.spacePart + 3
-	x := x1 - 2
+	s.cache.canvas.DrawLine(x1, lineTop, x1, lineBottom, s.cache.theme.Axis)
 	if !strings.Contains(n.String(), "#") {
-		s.drawable.DrawString(x+5, y, n.String(), s.axisColor)
-		y += s.spacePart*2 + 3
-		s.drawable.DrawString(x+5, y, fmt.Sprintf("%d", n.MidiNoteNumber()), s.axisColor)
+		style := s.tickStyle()
+		y := lineBottom + s.spacePart/2 + style.ascent()
+		s.cache.canvas.DrawText(x1+3, y, n.String(), style, s.cache.theme.Axis)
+		s.cache.canvas.DrawText(x1+3, y+style.lineHeight(), fmt.Sprintf("%d", n.MidiNoteNumber()), style, s.cache.theme.Axis)
 	}
 
 }
 
-//Draws the plot title
-func (s *SpectrumDrawer) drawPlotTitle(title string, lineTop int) {
-	x := s.labelSpace
-	y := lineTop + 3*s.spacePart
-	s.drawable.DrawString(x, y, title, s.titleColor)
+//Draws the plot title above the plot or at the top of the widget if the top margin is too small
+func (s *SpectrumDrawer) drawPlotTitle(title string, top int) {
+	style := s.titleStyle()
+	x := s.cache.x + s.cache.margins.left
+	y := max(top+s.cache.margins.top-s.spacePart-style.descent(), top+style.ascent())
+	s.cache.canvas.DrawText(x, y, title, style, s.cache.theme.Title)
 }
 
-//getWidgetWidth implements Widget interface
-func (s *SpectrumDrawer) getWidgetWidth() int {
-	s.cache = s.newSpectrumDrawerCache()
-	return s.cache.calculatedWidth
+//getTitle returns the title of the plot
+func (s *SpectrumDrawer) getTitle() string {
+	return s.title
+}
+
+//Validate checks the data of the plot. It is called before the plot is drawn
+func (s *SpectrumDrawer) Validate() error {
+	if len(s.frequencies) == 0 {
+		return fmt.Errorf("no frequencies: %w", ErrEmptyData)
+	}
+	if err := validatePoints("frequencies", s.frequencies, len(s.frequencies)); err != nil {
+		return err
+	}
+	if s.endFreq <= s.freqScale.lowerBound(s.startFreq) {
+		return fmt.Errorf("end frequency %fHz is too low for the frequency scale: %w", s.endFreq, ErrZeroRange)
+	}
+	for i, item := range s.items {
+		if err := validatePoints(fmt.Sprintf("item %d", i), item.points, len(s.frequencies)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-//getWidgetHeight implements Widget interface
-func (s *SpectrumDrawer) getWidgetHeight() int {
-	s.cache = s.newSpectrumDrawerCache()
-	return s.cache.calculatedHeight
+//Measure implements DrawerWidget interface
+func (s *SpectrumDrawer) Measure() Measurement {
+	return s.size.measure(s.DrawerBuilder, s.plotMargins())
+}
+
+//plotMargins returns the margins around the plot
+func (s *SpectrumDrawer) plotMargins() margins {
+	return s.legend().margins(s.size.plotMargins(s.DrawerBuilder, s.textMargins()))
+}
+
+//textMargins returns the margins fitting the title, the value labels and below the plot the notes with their MIDI
+//numbers and the start and end frequencies
+func (s *SpectrumDrawer) textMargins() margins {
+	tick := s.tickStyle()
+	axis := s.axisStyle()
+	notes := s.spacePart + s.spacePart/2 + tick.ascent() + tick.lineHeight() + tick.descent()
+	freqs := 5*s.spacePart + axis.ascent() + axis.descent()
+	m := margins{
+		top:    s.titleMargin(s.title, s.titleStyle()),
+		bottom: max(notes, freqs) + s.spacePart,
+	}
+	if r, ok := s.axisRange(s.newSharedRange()); ok {
+		m.left = s.yAxisTicks().width(r) + s.spacePart
+	}
+	return m
 }
 
 //Draws the y axis
 func (s *SpectrumDrawer) drawYAxis(top int) {
-	top += s.labelSpace
-	bottom := top + s.plotHeight + s.spacePart
-	x := s.labelSpace
+	top += s.cache.margins.top
+	bottom := top + s.cache.plotHeight + s.spacePart
+	x := s.cache.x + s.cache.margins.left
+	s.cache.canvas.DrawLine(x, top, x, bottom, s.cache.theme.Axis)
 
-	for y := top; y <= bottom; y++ {
-		s.drawable.Set(x, y, s.axisColor)
+	yRange, ok := s.axisRange(s.cache.sharedRange)
+	if !ok {
+		return
+	}
+	ticks := s.yAxisTicks()
+	ticks.canvas = s.cache.canvas
+	ticks.x = x
+	ticks.bottom = top + s.cache.plotHeight
+	ticks.height = s.cache.plotHeight
+	ticks.color = s.cache.theme.Axis
+	ticks.draw(yRange)
+}
+
+//yAxisTicks returns the ticks of the y-axis for a plot of the height set in the DrawerBuilder or the widget
+func (s *SpectrumDrawer) yAxisTicks() yAxisTicks {
+	unit := s.yUnit
+	if unit == "" && s.yScale == YScaleDecibel {
+		unit = "dB"
+	}
+	return yAxisTicks{
+		height:     s.size.plotHeight(s.DrawerBuilder),
+		tickLength: s.spacePart,
+		style:      s.tickStyle(),
+		unit:       unit,
+		formatter:  s.yFormatter,
 	}
+}
 
+//axisRange returns the range labeled on the y-axis. With YScaleLinear every item has its own range, so the y-axis is
+//labeled with the range of the first item. With YScaleShared it is the shared range of all items
+func (s *SpectrumDrawer) axisRange(shared yRange) (yRange, bool) {
+	switch s.yScale {
+	case YScaleLinear:
+		if len(s.items) == 0 {
+			return yRange{}, false
+		}
+		return s.itemRange(s.items[0]), true
+	case YScaleShared:
+		return shared, true
+	default:
+		return s.itemRange(SpectrumDrawerItems{}), true
+	}
 }
@@ -0,0 +1,23 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"image"
+	"testing"
+)
+
+func TestLogFrequencyScaleOctaveWidth(t *testing.T) {
+	for _, scale := range []FrequencyScale{FrequencyScaleLog2, FrequencyScaleLog10} {
+		spec := NewSpectrumDrawer(NewDrawer(), make([]float64, 0), "").StartFreq(25).EndFreq(25600).FreqScale(scale)
+		size := spec.Measure()
+		spec.cache = spec.newSpectrumDrawerCache(image.Rect(0, 0, size.Width, size.Height))
+		if x := spec.freqToX(25); x != spec.labelSpace {
+			t.Errorf("expected start frequency at the y-axis, got x=%d", x)
+		}
+		want := spec.plotWidth / 10
+		for f := 25.0; f < 25600; f *= 2 {
+			if w := spec.freqToX(f*2) - spec.freqToX(f); w < want-1 || w > want+1 {
+				t.Errorf("expected octave %fHz to be %d pixels wide, got %d", f, want, w)
+			}
+		}
+	}
+}
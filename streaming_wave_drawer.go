@@ -0,0 +1,506 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"reflect"
+	"time"
+)
+
+//StreamingWaveDrawer is a widget that draws the latest samples of a WaveStream like an oscilloscope. The shown time
+//window follows the end of the stream, so the plot scrolls while samples are pushed. The axes are fixed, with the time
+//relative to the latest sample and a fixed value range.
+//Drawn again to the same canvas and region, only the plot area is redrawn or with Sweep only the columns of the new
+//samples. Every pixel column is summarized once, so redrawing is cheap even for long windows
+type StreamingWaveDrawer struct {
+	*DrawerBuilder
+	size           widgetSize
+	cache          *streamingWaveDrawerCache
+	title          string
+	stream         *WaveStream
+	window         time.Duration
+	valueRange     yRange
+	names          []string
+	itemColors     []color.Color
+	colors         widgetTheme
+	sweep          bool
+	yUnit          string
+	yFormatter     func(value float64) string
+	legendPosition LegendPosition
+	columns        *streamColumns
+	drawn          *streamDrawn
+}
+
+//NewStreamingWaveDrawer is the constructor for StreamingWaveDrawer showing all samples the stream keeps with values
+//from -1 to 1
+func NewStreamingWaveDrawer(drawer *DrawerBuilder, stream *WaveStream, title string) *StreamingWaveDrawer {
+	s := &StreamingWaveDrawer{
+		DrawerBuilder: drawer,
+		title:         title,
+		stream:        stream,
+		valueRange:    newYRange(-1, 1),
+	}
+	if stream != nil {
+		s.window = time.Duration(float64(stream.capacity) / float64(stream.sampleRate) * float64(time.Second))
+	}
+	return s
+}
+
+//streamingWaveDrawerCache contains data that is recalculated often during drawing
+type streamingWaveDrawerCache struct {
+	ctx              context.Context
+	x                int
+	theme            Theme
+	canvas           VectorDrawable
+	margins          margins
+	plotWidth        int
+	plotHeight       int
+	pushed           int64
+	newest           int64
+	calculatedWidth  int
+	calculatedHeight int
+}
+
+//streamColumns is a ring with the envelope of every pixel column of the shown window per channel. Column c contains
+//the samples from c*samplesPerColumn up to (c+1)*samplesPerColumn, counted since the stream was created
+type streamColumns struct {
+	samplesPerColumn float64
+	width            int
+	channels         [][]waveEnvelopeColumn
+	//last is the newest summarized column, which can still get more samples
+	last int64
+}
+
+//streamDrawn is what was drawn last, so the next draw can redraw only what changed
+type streamDrawn struct {
+	canvas VectorDrawable
+	region image.Rectangle
+	pushed int64
+	newest int64
+}
+
+//Window sets the shown time. Default is the time of all samples the stream keeps
+func (s *StreamingWaveDrawer) Window(window time.Duration) *StreamingWaveDrawer {
+	s.window = window
+	return s.Redraw()
+}
+
+//YRange sets the fixed range of the values. Default is -1 to 1
+func (s *StreamingWaveDrawer) YRange(min float64, max float64) *StreamingWaveDrawer {
+	s.valueRange = newYRange(min, max)
+	return s.Redraw()
+}
+
+//ChannelNames sets the names of the channels shown in the legend. Default is no name, which hides the channels in
+//the legend
+func (s *StreamingWaveDrawer) ChannelNames(names ...string) *StreamingWaveDrawer {
+	s.names = names
+	return s.Redraw()
+}
+
+//ChannelColors sets the colors of the channels. Channels without color use the palette of the theme
+func (s *StreamingWaveDrawer) ChannelColors(colors ...color.Color) *StreamingWaveDrawer {
+	s.itemColors = colors
+	return s.Redraw()
+}
+
+//Sweep sets if new samples are drawn from left to right over the oldest ones, like a heart monitor, instead of
+//scrolling the plot. Only the columns of new samples are redrawn then. Default is false
+func (s *StreamingWaveDrawer) Sweep(sweep bool) *StreamingWaveDrawer {
+	s.sweep = sweep
+	return s.Redraw()
+}
+
+//Theme sets the colors of this plot, replacing the theme of the DrawerBuilder. Colors set with the color setters
+//still override the theme
+func (s *StreamingWaveDrawer) Theme(theme Theme) *StreamingWaveDrawer {
+	s.colors.theme = &theme
+	return s.Redraw()
+}
+
+//BackgroundColor sets the background-color of the plot. Default is the background of the theme
+func (s *StreamingWaveDrawer) BackgroundColor(backgroundColor color.Color) *StreamingWaveDrawer {
+	s.colors.background = backgroundColor
+	return s.Redraw()
+}
+
+//DividerColor sets the divider-color of the plot. Default is the divider color of the theme
+func (s *StreamingWaveDrawer) DividerColor(dividerColor color.Color) *StreamingWaveDrawer {
+	s.colors.divider = dividerColor
+	return s.Redraw()
+}
+
+//AxisColor sets the color of the axis. Default is the axis color of the theme
+func (s *StreamingWaveDrawer) AxisColor(axisColor color.Color) *StreamingWaveDrawer {
+	s.colors.axis = axisColor
+	return s.Redraw()
+}
+
+//TitleColor sets the color of the title. Default is the title color of the theme
+func (s *StreamingWaveDrawer) TitleColor(titleColor color.Color) *StreamingWaveDrawer {
+	s.colors.title = titleColor
+	return s.Redraw()
+}
+
+//Width sets the width of the plot without margins. Default is the plot width of the DrawerBuilder
+func (s *StreamingWaveDrawer) Width(width int) *StreamingWaveDrawer {
+	s.size.width = max(width, 0)
+	return s.Redraw()
+}
+
+//Height sets the height of the plot without margins. Default is the plot height of the DrawerBuilder
+func (s *StreamingWaveDrawer) Height(height int) *StreamingWaveDrawer {
+	s.size.height = max(height, 0)
+	return s.Redraw()
+}
+
+//Margins sets the space around the plot for the title and the axis labels. Default is the space measured to fit the
+//text, but at least the label space of the DrawerBuilder on all sides
+func (s *StreamingWaveDrawer) Margins(top int, right int, bottom int, left int) *StreamingWaveDrawer {
+	s.size.setMargins(top, right, bottom, left)
+	return s.Redraw()
+}
+
+//Legend sets where the legend with the names of the channels is drawn. Default is LegendTopRight
+func (s *StreamingWaveDrawer) Legend(position LegendPosition) *StreamingWaveDrawer {
+	s.legendPosition = position
+	return s.Redraw()
+}
+
+//YUnit sets the unit appended to the value labels of the y-axis. Default is no unit
+func (s *StreamingWaveDrawer) YUnit(yUnit string) *StreamingWaveDrawer {
+	s.yUnit = yUnit
+	return s.Redraw()
+}
+
+//YFormatter sets a function formatting the value labels of the y-axis. It replaces the default formatting with YUnit
+func (s *StreamingWaveDrawer) YFormatter(yFormatter func(value float64) string) *StreamingWaveDrawer {
+	s.yFormatter = yFormatter
+	return s.Redraw()
+}
+
+//Redraw makes the next Draw draw the whole widget. Call it if the canvas was changed by others or the DrawerBuilder got
+//new settings since the last Draw
+func (s *StreamingWaveDrawer) Redraw() *StreamingWaveDrawer {
+	s.drawn = nil
+	return s
+}
+
+//newStreamingWaveDrawerCache creates a new cache with pre-calculated values for plotting to the region bounds
+func (s *StreamingWaveDrawer) newStreamingWaveDrawerCache(bounds image.Rectangle) *streamingWaveDrawerCache {
+	m := s.plotMargins()
+	return &streamingWaveDrawerCache{
+		x:                bounds.Min.X,
+		theme:            s.colors.resolve(s.DrawerBuilder),
+		margins:          m,
+		plotWidth:        bounds.Dx() - m.left - m.right,
+		plotHeight:       bounds.Dy() - m.top - m.bottom,
+		calculatedWidth:  bounds.Dx(),
+		calculatedHeight: bounds.Dy(),
+	}
+}
+
+//Draw implements DrawerWidget interface
+func (s *StreamingWaveDrawer) Draw(region image.Rectangle, canvas VectorDrawable) error {
+	return s.DrawContext(context.Background(), region, canvas)
+}
+
+//DrawContext implements ContextDrawerWidget interface
+func (s *StreamingWaveDrawer) DrawContext(ctx context.Context, region image.Rectangle, canvas VectorDrawable) error {
+	y := region.Min.Y
+	s.cache = s.newStreamingWaveDrawerCache(region)
+	s.cache.ctx = ctx
+	s.cache.canvas = canvas
+	if err := s.updateColumns(); err != nil {
+		return err
+	}
+
+	drawn := s.drawn
+	s.drawn = nil
+	if drawn == nil || drawn.region != region || !sameCanvas(drawn.canvas, canvas) {
+		s.drawBackground(y)
+		s.drawPlotTitle(s.title, y)
+		s.drawXAxis(y)
+		s.drawYAxis(y)
+		s.drawColumns(y, 0, s.cache.plotWidth)
+	} else if s.sweep && drawn.newest >= 0 && s.cache.newest-drawn.newest < int64(s.cache.plotWidth-s.sweepGap()) {
+		//The column of the previously newest sample can have got more samples since
+		first := s.columnX(drawn.newest)
+		last := s.columnX(s.cache.newest + int64(s.sweepGap()))
+		if first <= last {
+			s.drawColumns(y, first, last+1)
+		} else {
+			s.drawColumns(y, first, s.cache.plotWidth)
+			s.drawColumns(y, 0, last+1)
+		}
+	} else if drawn.pushed != s.cache.pushed {
+		s.drawColumns(y, 0, s.cache.plotWidth)
+	}
+	s.drawDivider(y)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.drawn = &streamDrawn{canvas: canvas, region: region, pushed: s.cache.pushed, newest: s.cache.newest}
+	return nil
+}
+
+//updateColumns summarizes the samples of the columns that got new samples since the last draw
+func (s *StreamingWaveDrawer) updateColumns() error {
+	width := s.cache.plotWidth
+	samplesPerColumn := s.window.Seconds() * float64(s.stream.sampleRate) / float64(width)
+	c := s.columns
+	if c == nil || c.width != width || c.samplesPerColumn != samplesPerColumn || len(c.channels) != len(s.stream.channels) {
+		c = &streamColumns{samplesPerColumn: samplesPerColumn, width: width, channels: make([][]waveEnvelopeColumn, len(s.stream.channels)), last: -1}
+		for i := range c.channels {
+			c.channels[i] = make([]waveEnvelopeColumn, width)
+		}
+		s.columns = c
+	}
+	pushed := s.stream.Pushed()
+	newest := int64(math.Floor(float64(pushed-1) / samplesPerColumn))
+	first := maxInt64(c.last, newest-int64(width)+1)
+	for column := maxInt64(first, 0); column <= newest; column++ {
+		if err := s.cache.ctx.Err(); err != nil {
+			return err
+		}
+		start := int64(math.Ceil(float64(column) * samplesPerColumn))
+		end := int64(math.Ceil(float64(column+1) * samplesPerColumn))
+		for i := range c.channels {
+			c.channels[i][column%int64(width)] = s.stream.envelope(i, start, end)
+		}
+	}
+	c.last = newest
+	s.cache.pushed = pushed
+	s.cache.newest = newest
+	return nil
+}
+
+//oldest returns the oldest column shown in the plot
+func (s *StreamingWaveDrawer) oldest() int64 {
+	return s.cache.newest - int64(s.cache.plotWidth) + 1
+}
+
+//columnX returns the position of a column relative to the left of the plot
+func (s *StreamingWaveDrawer) columnX(column int64) int {
+	if s.sweep {
+		return int(column % int64(s.cache.plotWidth))
+	}
+	return int(column - s.oldest())
+}
+
+//sweepGap returns the number of columns cleared right of the newest column with Sweep
+func (s *StreamingWaveDrawer) sweepGap() int {
+	return min(max(s.spacePart, 1), s.cache.plotWidth-1)
+}
+
+//drawColumns clears the plot between the columns at first up to last excluding, relative to the left of the plot, and
+//draws the envelope of all channels there. If the area contains the legend, the whole plot is redrawn
+func (s *StreamingWaveDrawer) drawColumns(y int, first int, last int) {
+	plot := s.plotBounds(y)
+	area := image.Rect(plot.Min.X+first, plot.Min.Y, plot.Min.X+last, plot.Max.Y+1)
+	l := s.legend()
+	if legendBox := l.bounds(plot); l.visible() && legendBox.Overlaps(area) && legendBox.In(plot) {
+		first, last = 0, s.cache.plotWidth
+		area = image.Rect(plot.Min.X, plot.Min.Y, plot.Max.X, plot.Max.Y+1)
+	}
+	s.cache.canvas.FillRect(area, s.cache.theme.Background)
+	for i := range s.columns.channels {
+		c := itemColor(nil, s.cache.theme, i)
+		if i < len(s.itemColors) && s.itemColors[i] != nil {
+			c = s.itemColors[i]
+		}
+		s.drawChannel(i, plot, first, last, c)
+	}
+	middle := plot.Min.Y + s.cache.plotHeight/2
+	s.cache.canvas.DrawLine(area.Min.X, middle, area.Max.X-1, middle, s.cache.theme.Axis)
+	if first == 0 {
+		s.cache.canvas.DrawLine(plot.Min.X, plot.Min.Y, plot.Min.X, plot.Max.Y, s.cache.theme.Axis)
+	}
+	if first == 0 && last == s.cache.plotWidth {
+		l.draw(s.cache.canvas, plot)
+	}
+}
+
+//drawChannel draws a vertical line from the minimum to the maximum of every column between first and last, extended
+//to the last sample of the previous column like the envelope of a WaveDrawer
+func (s *StreamingWaveDrawer) drawChannel(channel int, plot image.Rectangle, first int, last int, c color.Color) {
+	columns := s.columns.channels[channel]
+	for column := s.oldest(); column <= s.cache.newest; column++ {
+		x := s.columnX(column)
+		if column < 0 || x < first || x >= last {
+			continue
+		}
+		if s.sweep && s.cache.newest-column >= int64(s.cache.plotWidth-s.sweepGap()) {
+			continue
+		}
+		e := columns[column%int64(s.cache.plotWidth)]
+		if e.count == 0 {
+			continue
+		}
+		lo, hi := e.min, e.max
+		if previous := column - 1; previous >= 0 && previous >= s.oldest() {
+			if p := columns[previous%int64(s.cache.plotWidth)]; p.count > 0 {
+				lo = math.Min(lo, p.last)
+				hi = math.Max(hi, p.last)
+			}
+		}
+		bottom := plot.Max.Y
+		s.cache.canvas.DrawLine(plot.Min.X+x, s.valueRange.toY(hi, bottom, s.cache.plotHeight), plot.Min.X+x, s.valueRange.toY(lo, bottom, s.cache.plotHeight), c)
+	}
+}
+
+//drawBackground plots the background
+func (s *StreamingWaveDrawer) drawBackground(y int) {
+	bottom := y + s.cache.calculatedHeight
+	s.cache.canvas.FillRect(image.Rect(s.cache.x, y, s.cache.x+s.cache.calculatedWidth, bottom), s.cache.theme.Background)
+}
+
+//drawXAxis draws the time labels below the plot. Without Sweep the times are relative to the newest sample
+func (s *StreamingWaveDrawer) drawXAxis(y int) {
+	plot := s.plotBounds(y)
+	start := -s.window
+	if s.sweep {
+		start = 0
+	}
+	middle := plot.Min.Y + s.cache.plotHeight/2
+	s.cache.canvas.DrawLine(plot.Min.X-s.spacePart, middle, plot.Min.X, middle, s.cache.theme.Axis)
+	style := s.tickStyle()
+	for i := 0; i <= 5; i++ {
+		x := plot.Min.X + i*s.cache.plotWidth/5
+		t := start + s.window*time.Duration(i)/5
+		bottom := plot.Max.Y + s.spacePart*3
+		s.cache.canvas.DrawLine(x, plot.Max.Y, x, bottom, s.cache.theme.Axis)
+		label := timeLabel(t)
+		s.cache.canvas.DrawText(x-style.width(label)/2, bottom+s.spacePart/2+style.ascent(), label, style, s.cache.theme.Axis)
+	}
+}
+
+//drawYAxis draws the value labels left of the plot
+func (s *StreamingWaveDrawer) drawYAxis(y int) {
+	plot := s.plotBounds(y)
+	s.cache.canvas.DrawLine(plot.Min.X, plot.Max.Y, plot.Min.X, plot.Max.Y+s.spacePart, s.cache.theme.Axis)
+	ticks := s.yAxisTicks()
+	ticks.canvas = s.cache.canvas
+	ticks.x = plot.Min.X
+	ticks.bottom = plot.Max.Y
+	ticks.height = s.cache.plotHeight
+	ticks.color = s.cache.theme.Axis
+	ticks.draw(s.valueRange)
+}
+
+//drawDivider draws a line at the top of the plot if it isn't in the first row and at the left if it isn't in the
+//first column
+func (s *StreamingWaveDrawer) drawDivider(y int) {
+	x := s.cache.x
+	if y > 0 {
+		s.cache.canvas.DrawLine(x, y, x+s.cache.calculatedWidth-1, y, s.cache.theme.Divider)
+	}
+	if x > 0 {
+		s.cache.canvas.DrawLine(x, y, x, y+s.cache.calculatedHeight-1, s.cache.theme.Divider)
+	}
+}
+
+//Draws the plot title above the plot or at the top of the widget if the top margin is too small
+func (s *StreamingWaveDrawer) drawPlotTitle(title string, top int) {
+	style := s.titleStyle()
+	x := s.cache.x + s.cache.margins.left
+	y := max(top+s.cache.margins.top-s.spacePart-style.descent(), top+style.ascent())
+	s.cache.canvas.DrawText(x, y, title, style, s.cache.theme.Title)
+}
+
+//plotBounds returns the region of the plot without margins
+func (s *StreamingWaveDrawer) plotBounds(y int) image.Rectangle {
+	x := s.cache.x + s.cache.margins.left
+	y += s.cache.margins.top
+	return image.Rect(x, y, x+s.cache.plotWidth, y+s.cache.plotHeight)
+}
+
+//legend returns the legend of all named channels
+func (s *StreamingWaveDrawer) legend() legend {
+	theme := s.colors.resolve(s.DrawerBuilder)
+	entries := make([]legendEntry, 0)
+	for i, name := range s.names {
+		if name == "" {
+			continue
+		}
+		var c color.Color
+		if i < len(s.itemColors) {
+			c = s.itemColors[i]
+		}
+		entries = append(entries, legendEntry{name: name, color: itemColor(c, theme, i)})
+	}
+	return legend{
+		position:   s.legendPosition,
+		entries:    entries,
+		style:      s.tickStyle(),
+		padding:    s.spacePart,
+		background: theme.Background,
+		border:     theme.Divider,
+		textColor:  theme.Axis,
+	}
+}
+
+//getTitle returns the title of the plot
+func (s *StreamingWaveDrawer) getTitle() string {
+	return s.title
+}
+
+//Validate checks the data of the plot. It is called before the plot is drawn
+func (s *StreamingWaveDrawer) Validate() error {
+	if s.stream == nil {
+		return fmt.Errorf("no stream: %w", ErrEmptyData)
+	}
+	if s.window <= 0 {
+		return fmt.Errorf("window %v is not positive: %w", s.window, ErrZeroRange)
+	}
+	return nil
+}
+
+//Measure implements DrawerWidget interface
+func (s *StreamingWaveDrawer) Measure() Measurement {
+	return s.size.measure(s.DrawerBuilder, s.plotMargins())
+}
+
+//plotMargins returns the margins around the plot
+func (s *StreamingWaveDrawer) plotMargins() margins {
+	return s.legend().margins(s.size.plotMargins(s.DrawerBuilder, s.textMargins()))
+}
+
+//textMargins returns the margins fitting the title, the time labels and the value labels
+func (s *StreamingWaveDrawer) textMargins() margins {
+	start := -s.window
+	if s.sweep {
+		start = 0
+	}
+	m := s.timeAxisMargins(start, start+s.window)
+	m.top = s.titleMargin(s.title, s.titleStyle())
+	m.left = max(m.left, s.yAxisTicks().width(s.valueRange)+s.spacePart)
+	return m
+}
+
+//yAxisTicks returns the ticks of the y-axis for a plot of the height set in the DrawerBuilder or the widget
+func (s *StreamingWaveDrawer) yAxisTicks() yAxisTicks {
+	return yAxisTicks{
+		height:     s.size.plotHeight(s.DrawerBuilder),
+		tickLength: s.spacePart,
+		style:      s.tickStyle(),
+		unit:       s.yUnit,
+		formatter:  s.yFormatter,
+	}
+}
+
+//sameCanvas returns if a and b draw to the same target, even if one is a new region or adapter of it. Targets that
+//can't be compared are never the same
+func sameCanvas(a VectorDrawable, b VectorDrawable) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	targetA, targetB := canvasTarget(a), canvasTarget(b)
+	if targetA == nil || targetB == nil || reflect.TypeOf(targetA) != reflect.TypeOf(targetB) || !reflect.TypeOf(targetA).Comparable() {
+		return false
+	}
+	return targetA == targetB
+}
@@ -0,0 +1,166 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+//pushSine pushes count samples of a sine to all channels of stream, continuing at sample offset
+func pushSine(t *testing.T, stream *WaveStream, offset int, count int) {
+	if err := stream.Push(sineChannels(stream.Channels(), offset, count)...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+//sineChannels returns count samples of a sine with a different frequency and amplitude per channel, continuing at
+//sample offset
+func sineChannels(channels int, offset int, count int) [][]float64 {
+	samples := make([][]float64, channels)
+	for c := range samples {
+		samples[c] = make([]float64, count)
+		for i := range samples[c] {
+			samples[c][i] = math.Sin(float64(offset+i)/float64(10+c*7)) / float64(c+1)
+		}
+	}
+	return samples
+}
+
+func TestWaveStream(t *testing.T) {
+	stream := NewWaveStream(1000, 4, 2)
+	if err := stream.Push([]float64{1, 2, 3}, []float64{1}); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("expected channels of different length to fail, got %v", err)
+	}
+	if err := stream.Push([]float64{1}); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("expected a missing channel to fail, got %v", err)
+	}
+	_ = stream.Push([]float64{1, 2, 3}, []float64{-1, -2, -3})
+	_ = stream.Push([]float64{4, 5, 6}, []float64{-4, -5, -6})
+	if stream.Pushed() != 6 || stream.Duration() != 6*time.Millisecond {
+		t.Errorf("expected 6 samples in 6ms, got %d in %v", stream.Pushed(), stream.Duration())
+	}
+	if e := stream.envelope(0, 0, 6); e.count != 4 || e.min != 3 || e.max != 6 || e.last != 6 {
+		t.Errorf("expected only the latest 4 samples to be kept, got %+v", e)
+	}
+	_ = stream.Push([]float64{7, 8, 9, 10, 11}, []float64{0, 0, 0, 0, 0})
+	if e := stream.envelope(0, 0, 20); e.count != 4 || e.min != 8 || e.max != 11 {
+		t.Errorf("expected the latest 4 samples of a push larger than the capacity, got %+v", e)
+	}
+}
+
+func TestStreamingWaveDrawer(t *testing.T) {
+	for _, sweep := range []bool{false, true} {
+		stream := NewWaveStream(1000, 1000, 2)
+		newDrawer := func() (*DrawerBuilder, *Drawer) {
+			d := NewDrawer().PlotWidth(200).PlotHeight(60).LabelSpace(40)
+			wave := NewStreamingWaveDrawer(d, stream, "live").Window(500*time.Millisecond).ChannelNames("left", "right").Sweep(sweep)
+			drawer, err := d.AddPlot(wave).Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			return d, drawer
+		}
+		d, drawer := newDrawer()
+		img := image.NewRGBA(image.Rect(0, 0, drawer.GetWidth(), drawer.GetHeight()))
+		d.SetDrawable(NewImageDrawable(img))
+
+		offset := 0
+		for _, count := range []int{0, 30, 1, 77, 400, 5, 900, 3} {
+			pushSine(t, stream, offset, count)
+			offset += count
+			if err := drawer.Draw(); err != nil {
+				t.Fatal(err)
+			}
+			_, full := newDrawer()
+			want, err := full.Render()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(img.Pix, want.(*image.RGBA).Pix) {
+				t.Fatalf("expected redrawing after %d samples with sweep %v to draw the same as drawing everything", offset, sweep)
+			}
+		}
+	}
+
+	if err := NewStreamingWaveDrawer(NewDrawer(), nil, "").Validate(); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("expected a missing stream to fail, got %v", err)
+	}
+	if err := NewStreamingWaveDrawer(NewDrawer(), NewWaveStream(1000, 10, 1), "").Window(0).Validate(); !errors.Is(err, ErrZeroRange) {
+		t.Errorf("expected an empty window to fail, got %v", err)
+	}
+}
+
+func TestStreamingWaveDrawerRegions(t *testing.T) {
+	d := NewDrawer().PlotWidth(200).PlotHeight(60).LabelSpace(40).Parallelism(2)
+	stream := NewWaveStream(1000, 1000, 1)
+	wave := NewStreamingWaveDrawer(d, stream, "live").Sweep(true)
+	times := []time.Duration{0, time.Millisecond, 2 * time.Millisecond}
+	drawer, err := d.AddPlot(wave).AddPlot(NewWaveDrawer(d, times, "wave").SetItems(NewWaveDrawerItems([]float64{0, 1, -1}, nil))).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, drawer.GetWidth(), drawer.GetHeight()))
+	d.SetDrawable(NewImageDrawable(img))
+	pushSine(t, stream, 0, 100)
+	if err := drawer.Draw(); err != nil {
+		t.Fatal(err)
+	}
+
+	//The plots are drawn to new regions of the image every time. 50 more samples only redraw the columns from 19 to
+	//about 31 of the 5 samples each, so a marker at column 150 stays
+	bounds := wave.plotBounds(0)
+	marker := image.Pt(bounds.Min.X+150, bounds.Min.Y+bounds.Dy()/2)
+	background := img.RGBAAt(marker.X, marker.Y)
+	img.SetRGBA(marker.X, marker.Y, red)
+	pushSine(t, stream, 100, 50)
+	if err := drawer.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	if img.RGBAAt(marker.X, marker.Y) != red {
+		t.Error("expected only the columns of the new samples to be redrawn")
+	}
+
+	//Render draws to a new image, so everything is drawn
+	rendered, err := drawer.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	img.SetRGBA(marker.X, marker.Y, background)
+	if !bytes.Equal(img.Pix, rendered.(*image.RGBA).Pix) {
+		t.Error("expected the redrawn columns to draw the same as rendering everything")
+	}
+}
+
+func TestStreamingWaveDrawerConcurrentPush(t *testing.T) {
+	d := NewDrawer().PlotWidth(300).PlotHeight(60).LabelSpace(40)
+	stream := NewWaveStream(48000, 48000, 1)
+	drawer, err := d.AddPlot(NewStreamingWaveDrawer(d, stream, "live")).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	var pushErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			if pushErr = stream.Push(sineChannels(stream.Channels(), i*480, 480)...); pushErr != nil {
+				return
+			}
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		if _, err := drawer.Render(); err != nil {
+			t.Error(err)
+			break
+		}
+	}
+	wg.Wait()
+	if pushErr != nil {
+		t.Fatal(pushErr)
+	}
+}
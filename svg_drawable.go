@@ -0,0 +1,258 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+)
+
+//SVGDrawable is a VectorDrawable that records all drawing operations and writes them as a scalable SVG document.
+//Consecutive pixels of the same color are coalesced into lines and rectangles to keep the document compact
+type SVGDrawable struct {
+	width    int
+	height   int
+	elements []svgElement
+}
+
+//NewSVGDrawable is the constructor for SVGDrawable
+//width and height are the size of the document in pixels, usually GetWidth and GetHeight of the DrawerBuilder
+func NewSVGDrawable(width int, height int) *SVGDrawable {
+	return &SVGDrawable{
+		width:    width,
+		height:   height,
+		elements: make([]svgElement, 0),
+	}
+}
+
+//svgElement is a single recorded drawing operation
+type svgElement interface {
+	writeSVG(w io.Writer) error
+}
+
+//svgRect is a filled rectangle, created from one or more coalesced pixels
+type svgRect struct {
+	x     int
+	y     int
+	w     int
+	h     int
+	color color.NRGBA
+}
+
+//svgText is a string drawn with its baseline at y
+type svgText struct {
+	x      int
+	y      int
+	text   string
+	family string
+	size   float64
+	color  color.NRGBA
+}
+
+//svgShape is a polyline or polygon through points
+type svgShape struct {
+	tag    string
+	points []image.Point
+	color  color.NRGBA
+	filled bool
+}
+
+//Set implements Drawable interface
+func (s *SVGDrawable) Set(x, y int, c color.Color) {
+	if x < 0 || y < 0 || x >= s.width || y >= s.height {
+		return
+	}
+	s.addRect(svgRect{x: x, y: y, w: 1, h: 1, color: toNRGBA(c)})
+}
+
+//DrawString implements Drawable interface
+func (s *SVGDrawable) DrawString(x, y int, text string, c color.Color) {
+	s.elements = append(s.elements, &svgText{x: x, y: y, text: text, family: "monospace", size: 13, color: toNRGBA(c)})
+}
+
+//DrawText implements VectorDrawable interface. The font is referenced by its name with sans-serif as fallback
+func (s *SVGDrawable) DrawText(x, y int, text string, style TextStyle, c color.Color) {
+	s.elements = append(s.elements, &svgText{x: x, y: y, text: text, family: style.fontName() + ", sans-serif", size: style.Size, color: toNRGBA(c)})
+}
+
+//DrawLine implements VectorDrawable interface. Horizontal and vertical lines are recorded as rectangles so they can
+//be coalesced with neighbouring pixels
+func (s *SVGDrawable) DrawLine(x1, y1, x2, y2 int, c color.Color) {
+	if x1 == x2 || y1 == y2 {
+		s.FillRect(lineRect(x1, y1, x2, y2), c)
+		return
+	}
+	s.DrawPolyline([]image.Point{{X: x1, Y: y1}, {X: x2, Y: y2}}, c)
+}
+
+//FillRect implements VectorDrawable interface
+func (s *SVGDrawable) FillRect(r image.Rectangle, c color.Color) {
+	r = r.Canon().Intersect(image.Rect(0, 0, s.width, s.height))
+	if r.Empty() {
+		return
+	}
+	s.addRect(svgRect{x: r.Min.X, y: r.Min.Y, w: r.Dx(), h: r.Dy(), color: toNRGBA(c)})
+}
+
+//DrawPolyline implements VectorDrawable interface
+func (s *SVGDrawable) DrawPolyline(points []image.Point, c color.Color) {
+	if len(points) == 0 {
+		return
+	}
+	s.elements = append(s.elements, &svgShape{tag: "polyline", points: append([]image.Point(nil), points...), color: toNRGBA(c)})
+}
+
+//FillPolygon implements VectorDrawable interface
+func (s *SVGDrawable) FillPolygon(points []image.Point, c color.Color) {
+	if len(points) < 3 {
+		return
+	}
+	s.elements = append(s.elements, &svgShape{tag: "polygon", points: append([]image.Point(nil), points...), color: toNRGBA(c), filled: true})
+}
+
+//addRect appends a rectangle or grows the last recorded rectangle if they can be combined. A grown rectangle is
+//combined with its predecessor as well, so column-wise filled areas end up as one rectangle
+func (s *SVGDrawable) addRect(r svgRect) {
+	n := len(s.elements)
+	if n == 0 {
+		s.elements = append(s.elements, &r)
+		return
+	}
+	last, ok := s.elements[n-1].(*svgRect)
+	if !ok || !last.merge(r) {
+		s.elements = append(s.elements, &r)
+		return
+	}
+	for n >= 2 {
+		prev, ok := s.elements[n-2].(*svgRect)
+		if !ok || !prev.merge(*s.elements[n-1].(*svgRect)) {
+			return
+		}
+		s.elements = s.elements[:n-1]
+		n--
+	}
+}
+
+//merge grows the rectangle by o if both have the same color and together form a rectangle again
+func (s *svgRect) merge(o svgRect) bool {
+	if s.color != o.color {
+		return false
+	}
+	if o.x >= s.x && o.y >= s.y && o.x+o.w <= s.x+s.w && o.y+o.h <= s.y+s.h {
+		return true
+	}
+	if s.y == o.y && s.h == o.h {
+		if o.x == s.x+s.w {
+			s.w += o.w
+			return true
+		}
+		if o.x+o.w == s.x {
+			s.x = o.x
+			s.w += o.w
+			return true
+		}
+	}
+	if s.x == o.x && s.w == o.w {
+		if o.y == s.y+s.h {
+			s.h += o.h
+			return true
+		}
+		if o.y+o.h == s.y {
+			s.y = o.y
+			s.h += o.h
+			return true
+		}
+	}
+	return false
+}
+
+//writeSVG implements svgElement interface
+func (s *svgRect) writeSVG(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" %s/>\n", s.x, s.y, s.w, s.h, svgPaint("fill", s.color))
+	return err
+}
+
+//writeSVG implements svgElement interface. Lines run through the pixel centers, filled polygons through the pixel
+//corners, so both cover the same pixels as the rasterized version
+func (s *svgShape) writeSVG(w io.Writer) error {
+	var points strings.Builder
+	for i, p := range s.points {
+		if i > 0 {
+			points.WriteByte(' ')
+		}
+		if s.filled {
+			fmt.Fprintf(&points, "%d,%d", p.X, p.Y)
+		} else {
+			fmt.Fprintf(&points, "%g,%g", float64(p.X)+0.5, float64(p.Y)+0.5)
+		}
+	}
+	paint := svgPaint("fill", s.color) + " fill-rule=\"evenodd\""
+	if !s.filled {
+		paint = "fill=\"none\" " + svgPaint("stroke", s.color) + " stroke-width=\"1\" stroke-linecap=\"square\" stroke-linejoin=\"round\""
+	}
+	_, err := fmt.Fprintf(w, "<%s points=\"%s\" %s/>\n", s.tag, points.String(), paint)
+	return err
+}
+
+//writeSVG implements svgElement interface
+func (s *svgText) writeSVG(w io.Writer) error {
+	var text, family strings.Builder
+	if err := xml.EscapeText(&text, []byte(s.text)); err != nil {
+		return err
+	}
+	if err := xml.EscapeText(&family, []byte(s.family)); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "<text x=\"%d\" y=\"%d\" font-family=\"%s\" font-size=\"%g\" xml:space=\"preserve\" %s>%s</text>\n", s.x, s.y, family.String(), s.size, svgPaint("fill", s.color), text.String())
+	return err
+}
+
+//WriteTo writes the recorded drawing as SVG document to w. It implements io.WriterTo
+func (s *SVGDrawable) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	_, err := fmt.Fprintf(bw, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" shape-rendering=\"crispEdges\">\n", s.width, s.height, s.width, s.height)
+	if err != nil {
+		return cw.n, err
+	}
+	for _, e := range s.elements {
+		if err := e.writeSVG(bw); err != nil {
+			return cw.n, err
+		}
+	}
+	if _, err := bw.WriteString("</svg>\n"); err != nil {
+		return cw.n, err
+	}
+	err = bw.Flush()
+	return cw.n, err
+}
+
+//svgPaint formats a fill or stroke attribute for c including the opacity if it is not opaque
+func svgPaint(attribute string, c color.NRGBA) string {
+	paint := fmt.Sprintf("%s=\"#%02x%02x%02x\"", attribute, c.R, c.G, c.B)
+	if c.A != 255 {
+		paint += fmt.Sprintf(" %s-opacity=\"%.3f\"", attribute, float64(c.A)/255)
+	}
+	return paint
+}
+
+//toNRGBA converts any color to a non-alpha-premultiplied color as used by SVG
+func toNRGBA(c color.Color) color.NRGBA {
+	return color.NRGBAModel.Convert(c).(color.NRGBA)
+}
+
+//countingWriter counts the bytes written to w
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+//Write implements io.Writer interface
+func (s *countingWriter) Write(p []byte) (int, error) {
+	n, err := s.w.Write(p)
+	s.n += int64(n)
+	return n, err
+}
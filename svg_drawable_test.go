@@ -0,0 +1,37 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"bytes"
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestSVGDrawableCoalescesPixels(t *testing.T) {
+	svg := NewSVGDrawable(20, 10)
+	for x := 0; x < 20; x++ {
+		for y := 0; y < 10; y++ {
+			svg.Set(x, y, image.Black.C)
+		}
+	}
+	svg.DrawString(2, 8, "a<b", image.White.C)
+
+	var buf bytes.Buffer
+	n, err := svg.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo returned %d bytes, wrote %d", n, buf.Len())
+	}
+	out := buf.String()
+	if c := strings.Count(out, "<rect"); c != 1 {
+		t.Errorf("expected background to be coalesced into 1 rect, got %d", c)
+	}
+	if !strings.Contains(out, `width="20" height="10"`) {
+		t.Errorf("expected coalesced rect to cover the canvas: %s", out)
+	}
+	if !strings.Contains(out, ">a&lt;b</text>") {
+		t.Errorf("expected escaped text: %s", out)
+	}
+}
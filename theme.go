@@ -0,0 +1,131 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"image"
+	"image/color"
+)
+
+//Theme contains the colors of the plots. It is set for all plots with DrawerBuilder.Theme and can be replaced for a
+//single plot with the Theme setter of the widget. Colors set with the color setters of a widget override the theme
+type Theme struct {
+	Background color.Color
+	Divider    color.Color
+	Axis       color.Color
+	Title      color.Color
+	//Palette colors items created without a color, the first item gets the first color and so on
+	Palette []color.Color
+	//ColorMap maps magnitudes of spectrograms, normalized to [0,1], to colors
+	ColorMap func(v float64) color.Color
+}
+
+//DarkTheme returns white axes on a black background. This is the default
+func DarkTheme() Theme {
+	return Theme{
+		Background: image.Black.C,
+		Divider:    gray,
+		Axis:       image.White.C,
+		Title:      image.White.C,
+		Palette:    channelColors,
+		ColorMap:   heatColor,
+	}
+}
+
+//LightTheme returns black axes on a white background
+func LightTheme() Theme {
+	return Theme{
+		Background: image.White.C,
+		Divider:    color.RGBA{A: 255, R: 190, G: 190, B: 190},
+		Axis:       image.Black.C,
+		Title:      image.Black.C,
+		Palette: []color.Color{
+			color.RGBA{A: 255, R: 0, G: 114, B: 178},
+			color.RGBA{A: 255, R: 213, G: 94, B: 0},
+			color.RGBA{A: 255, R: 0, G: 158, B: 115},
+			color.RGBA{A: 255, R: 204, G: 121, B: 167},
+			color.RGBA{A: 255, R: 86, G: 180, B: 233},
+			color.RGBA{A: 255, R: 230, G: 159, B: 0},
+		},
+		ColorMap: heatColor,
+	}
+}
+
+//PrintTheme returns a monochrome theme for printing: black and gray on white, with spectrograms from white to black
+func PrintTheme() Theme {
+	return Theme{
+		Background: image.White.C,
+		Divider:    gray,
+		Axis:       image.Black.C,
+		Title:      image.Black.C,
+		Palette: []color.Color{
+			image.Black.C,
+			color.RGBA{A: 255, R: 100, G: 100, B: 100},
+			color.RGBA{A: 255, R: 160, G: 160, B: 160},
+		},
+		ColorMap: GrayscaleColormap().Reversed().At,
+	}
+}
+
+//HighContrastTheme returns saturated colors and white dividers on a black background for projectors and low vision
+func HighContrastTheme() Theme {
+	return Theme{
+		Background: image.Black.C,
+		Divider:    image.White.C,
+		Axis:       image.White.C,
+		Title:      yellow,
+		Palette: []color.Color{
+			yellow,
+			color.RGBA{A: 255, R: 0, G: 255, B: 255},
+			color.RGBA{A: 255, R: 255, G: 0, B: 255},
+			color.RGBA{A: 255, R: 0, G: 255, B: 0},
+		},
+		ColorMap: heatColor,
+	}
+}
+
+//itemColor returns c or the color of the palette of theme for the item at index if c is nil
+func itemColor(c color.Color, theme Theme, index int) color.Color {
+	if c != nil {
+		return c
+	}
+	if len(theme.Palette) == 0 {
+		return channelColors[index%len(channelColors)]
+	}
+	return theme.Palette[index%len(theme.Palette)]
+}
+
+//widgetTheme contains the theme and the colors set on a single widget, replacing the ones of the DrawerBuilder
+type widgetTheme struct {
+	theme      *Theme
+	background color.Color
+	divider    color.Color
+	axis       color.Color
+	title      color.Color
+	colorMap   func(v float64) color.Color
+}
+
+//resolve returns the theme of the DrawerBuilder or the one of the widget with the colors of the widget applied
+func (s widgetTheme) resolve(drawer *DrawerBuilder) Theme {
+	t := drawer.theme
+	if s.theme != nil {
+		t = *s.theme
+	}
+	if s.background != nil {
+		t.Background = s.background
+	}
+	if s.divider != nil {
+		t.Divider = s.divider
+	}
+	if s.axis != nil {
+		t.Axis = s.axis
+	}
+	if s.title != nil {
+		t.Title = s.title
+	}
+	if s.colorMap != nil {
+		t.ColorMap = s.colorMap
+	}
+	if t.ColorMap == nil {
+		t.ColorMap = heatColor
+	}
+	return t
+}
@@ -0,0 +1,78 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestWidgetTheme(t *testing.T) {
+	d := NewDrawer().PlotWidth(100).PlotHeight(50).Theme(LightTheme())
+	times := []time.Duration{0, time.Millisecond}
+	inherited := NewWaveDrawer(d, times, "inherited")
+	own := NewWaveDrawer(d, times, "own").Theme(PrintTheme()).AxisColor(yellow)
+
+	if theme := inherited.colors.resolve(d); theme.Background != image.White.C || theme.Axis != image.Black.C {
+		t.Errorf("expected the light theme of the drawer, got %+v", theme)
+	}
+	theme := own.colors.resolve(d)
+	if theme.Axis != yellow || theme.Title != image.Black.C {
+		t.Errorf("expected the print theme with a yellow axis, got %+v", theme)
+	}
+	if c := theme.ColorMap(1); !sameColor(c, image.Black.C) {
+		t.Errorf("expected the print color map to end black, got %v", c)
+	}
+
+	//The theme of the drawer is used even if it is set after the widgets are created
+	d.Theme(HighContrastTheme())
+	if theme := inherited.colors.resolve(d); theme.Title != yellow {
+		t.Errorf("expected the high contrast theme, got %+v", theme)
+	}
+}
+
+func TestItemColor(t *testing.T) {
+	theme := PrintTheme()
+	if c := itemColor(yellow, theme, 0); c != yellow {
+		t.Errorf("expected the color of the item, got %v", c)
+	}
+	if c := itemColor(nil, theme, 4); c != theme.Palette[1] {
+		t.Errorf("expected the second color of the palette, got %v", c)
+	}
+	if c := itemColor(nil, Theme{}, 1); c != channelColors[1] {
+		t.Errorf("expected the default palette without a palette, got %v", c)
+	}
+}
+
+func TestThemeRender(t *testing.T) {
+	d := NewDrawer().PlotWidth(100).PlotHeight(50).LabelSpace(40).Theme(LightTheme())
+	d.AddPlot(NewWaveDrawer(d, []time.Duration{0, time.Millisecond}, "wave").
+		SetItems(NewWaveDrawerItems([]float64{0, 1}, nil).Style(WaveDrawStyleLines)))
+	dr, err := d.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := dr.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := img.At(2, 2); !sameColor(c, image.White.C) {
+		t.Errorf("expected a white background, got %v", c)
+	}
+	found := false
+	for x := 40; x < 140 && !found; x++ {
+		for y := 40; y < 90 && !found; y++ {
+			found = sameColor(img.At(x, y), LightTheme().Palette[0])
+		}
+	}
+	if !found {
+		t.Error("expected the item in the first color of the palette")
+	}
+}
+
+//sameColor returns if a and b are the same color, ignoring the color model
+func sameColor(a color.Color, b color.Color) bool {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	return ar == br && ag == bg && ab == bb && aa == ba
+}
@@ -0,0 +1,100 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+)
+
+//niceTicks returns at most maxTicks evenly spaced values between min and max. The step between the values is
+//1, 2 or 5 times a power of ten, so the values are easy to read
+func niceTicks(min float64, max float64, maxTicks int) []float64 {
+	if maxTicks < 2 || !(max > min) || math.IsInf(max-min, 0) {
+		return nil
+	}
+	step := niceStep((max - min) / float64(maxTicks-1))
+	ticks := make([]float64, 0, maxTicks)
+	for i := math.Ceil(min / step); i*step <= max+step*1e-9; i++ {
+		v := i * step
+		if math.Abs(v) < step*1e-9 {
+			v = 0
+		}
+		ticks = append(ticks, v)
+	}
+	return ticks
+}
+
+//niceStep rounds step up to 1, 2 or 5 times a power of ten
+func niceStep(step float64) float64 {
+	magnitude := math.Pow(10, math.Floor(math.Log10(step)))
+	for _, f := range []float64{1, 2, 5} {
+		if f*magnitude >= step {
+			return f * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+//tickDecimals returns the number of decimals needed to distinguish ticks with the given values
+func tickDecimals(ticks []float64) int {
+	if len(ticks) < 2 {
+		return 0
+	}
+	return max(0, -int(math.Floor(math.Log10(ticks[1]-ticks[0]))))
+}
+
+//yAxisTicks draws ticks with value labels left of a vertical axis at x, or right of it if labelsRight is set
+type yAxisTicks struct {
+	canvas      VectorDrawable
+	x           int
+	bottom      int
+	height      int
+	tickLength  int
+	color       color.Color
+	style       TextStyle
+	unit        string
+	formatter   func(value float64) string
+	labelsRight bool
+}
+
+//labels returns all nice values in the range, leaving at least two lines of text between them, and their labels. The
+//labels are formatted with the formatter if set or with the needed decimals and the unit otherwise
+func (s yAxisTicks) labels(r yRange) ([]float64, []string) {
+	ticks := niceTicks(r.min, r.max, max(2, s.height/(3*s.style.lineHeight())))
+	decimals := tickDecimals(ticks)
+	labels := make([]string, len(ticks))
+	for i, v := range ticks {
+		labels[i] = strconv.FormatFloat(v, 'f', decimals, 64) + s.unit
+		if s.formatter != nil {
+			labels[i] = s.formatter(v)
+		}
+	}
+	return ticks, labels
+}
+
+//width returns the width in pixels of the ticks with the widest label beside the axis
+func (s yAxisTicks) width(r yRange) int {
+	_, labels := s.labels(r)
+	width := 0
+	for _, label := range labels {
+		width = max(width, s.style.width(label))
+	}
+	return s.tickLength + 3 + width
+}
+
+//draw draws the ticks with the labels for all nice values in the range
+func (s yAxisTicks) draw(r yRange) {
+	ticks, labels := s.labels(r)
+	center := (s.style.ascent() - s.style.descent()) / 2
+	for i, v := range ticks {
+		y := r.unitToY(v, s.bottom, s.height)
+		label := labels[i]
+		if s.labelsRight {
+			s.canvas.DrawLine(s.x, y, s.x+s.tickLength, y, s.color)
+			s.canvas.DrawText(s.x+s.tickLength+3, y+center, label, s.style, s.color)
+			continue
+		}
+		s.canvas.DrawLine(s.x-s.tickLength, y, s.x, y, s.color)
+		s.canvas.DrawText(s.x-s.tickLength-3-s.style.width(label), y+center, label, s.style, s.color)
+	}
+}
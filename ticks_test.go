@@ -0,0 +1,24 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNiceTicks(t *testing.T) {
+	cases := []struct {
+		min, max float64
+		maxTicks int
+		want     []float64
+	}{
+		{0, 10, 6, []float64{0, 2, 4, 6, 8, 10}},
+		{-120, 0, 7, []float64{-120, -100, -80, -60, -40, -20, 0}},
+		{-0.93, 0.87, 5, []float64{-0.5, 0, 0.5}},
+		{1, 1, 5, nil},
+	}
+	for _, c := range cases {
+		if got := niceTicks(c.min, c.max, c.maxTicks); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("niceTicks(%f, %f, %d) = %v, want %v", c.min, c.max, c.maxTicks, got, c.want)
+		}
+	}
+}
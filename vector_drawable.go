@@ -0,0 +1,227 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"golang.org/x/image/font"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+//VectorDrawable is a Drawable that can draw primitives at once instead of pixel by pixel. Vector backends like
+//SVGDrawable produce compact output and raster backends like ImageDrawable can use fast fills.
+//All coordinates are pixels, lines include both end points and rectangles exclude r.Max like image.Rectangle
+type VectorDrawable interface {
+	Drawable
+	//DrawLine draws a one pixel wide line from (x1,y1) to (x2,y2)
+	DrawLine(x1, y1, x2, y2 int, c color.Color)
+	//FillRect fills the rectangle r
+	FillRect(r image.Rectangle, c color.Color)
+	//DrawPolyline draws connected one pixel wide lines through all points
+	DrawPolyline(points []image.Point, c color.Color)
+	//FillPolygon fills the closed polygon through all points using the even-odd rule
+	FillPolygon(points []image.Point, c color.Color)
+	//DrawText draws text with its baseline starting at (x,y)
+	DrawText(x, y int, text string, style TextStyle, c color.Color)
+}
+
+//NewVectorDrawable returns drawable itself if it implements VectorDrawable. Pixel-only drawables are wrapped into an
+//adapter that rasterizes all primitives using Set
+func NewVectorDrawable(drawable Drawable) VectorDrawable {
+	if drawable == nil {
+		return nil
+	}
+	if v, ok := drawable.(VectorDrawable); ok {
+		return v
+	}
+	return &pixelVectorDrawable{Drawable: drawable}
+}
+
+//pixelVectorDrawable rasterizes the primitives of VectorDrawable for drawables that only support Set
+type pixelVectorDrawable struct {
+	Drawable
+}
+
+//drawTarget implements drawTarget interface. The adapter draws to the target of the wrapped drawable
+func (s *pixelVectorDrawable) drawTarget() any {
+	return canvasTarget(s.Drawable)
+}
+
+//DrawLine implements VectorDrawable interface
+func (s *pixelVectorDrawable) DrawLine(x1, y1, x2, y2 int, c color.Color) {
+	rasterizeLine(x1, y1, x2, y2, func(x, y int) {
+		s.Set(x, y, c)
+	})
+}
+
+//FillRect implements VectorDrawable interface
+func (s *pixelVectorDrawable) FillRect(r image.Rectangle, c color.Color) {
+	r = r.Canon()
+	for x := r.Min.X; x < r.Max.X; x++ {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			s.Set(x, y, c)
+		}
+	}
+}
+
+//DrawPolyline implements VectorDrawable interface
+func (s *pixelVectorDrawable) DrawPolyline(points []image.Point, c color.Color) {
+	rasterizePolyline(points, func(x1, y1, x2, y2 int) {
+		s.DrawLine(x1, y1, x2, y2, c)
+	})
+}
+
+//FillPolygon implements VectorDrawable interface
+func (s *pixelVectorDrawable) FillPolygon(points []image.Point, c color.Color) {
+	rasterizePolygon(points, func(x1, x2, y int) {
+		s.FillRect(image.Rect(x1, y, x2, y+1), c)
+	})
+}
+
+//DrawText implements VectorDrawable interface. Pixels covered by at least half are set, because the pixels behind
+//the text are unknown and can't be blended
+func (s *pixelVectorDrawable) DrawText(x, y int, text string, style TextStyle, c color.Color) {
+	var mask *image.Alpha
+	style.withFace(func(face font.Face) {
+		bounds, _ := font.BoundString(face, text)
+		mask = image.NewAlpha(image.Rect(bounds.Min.X.Floor(), bounds.Min.Y.Floor(), bounds.Max.X.Ceil(), bounds.Max.Y.Ceil()))
+		(&font.Drawer{Dst: mask, Src: image.Opaque, Face: face}).DrawString(text)
+	})
+	r := mask.Bounds()
+	for my := r.Min.Y; my < r.Max.Y; my++ {
+		for mx := r.Min.X; mx < r.Max.X; mx++ {
+			if mask.AlphaAt(mx, my).A >= 0x80 {
+				s.Set(x+mx, y+my, c)
+			}
+		}
+	}
+}
+
+//rasterizeLine calls set for every pixel of the line from (x1,y1) to (x2,y2) using Bresenham's algorithm
+func rasterizeLine(x1, y1, x2, y2 int, set func(x, y int)) {
+	dx := abs(x2 - x1)
+	dy := -abs(y2 - y1)
+	sx := 1
+	if x1 > x2 {
+		sx = -1
+	}
+	sy := 1
+	if y1 > y2 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		set(x1, y1)
+		if x1 == x2 && y1 == y2 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x1 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y1 += sy
+		}
+	}
+}
+
+//vector is a point with sub-pixel precision
+type vector struct {
+	x float64
+	y float64
+}
+
+//rasterizeSmoothLine calls plot for every pixel touched by the anti-aliased line from a to b using Xiaolin Wu's
+//algorithm. coverage is the part of the pixel covered by the line in (0,1]
+func rasterizeSmoothLine(a vector, b vector, plot func(x, y int, coverage float64)) {
+	steep := math.Abs(b.y-a.y) > math.Abs(b.x-a.x)
+	if steep {
+		a.x, a.y = a.y, a.x
+		b.x, b.y = b.y, b.x
+	}
+	if a.x > b.x {
+		a, b = b, a
+	}
+	set := func(x, y int, coverage float64) {
+		if coverage <= 0 {
+			return
+		}
+		if steep {
+			plot(y, x, coverage)
+		} else {
+			plot(x, y, coverage)
+		}
+	}
+	gradient := 1.0
+	if dx := b.x - a.x; dx != 0 {
+		gradient = (b.y - a.y) / dx
+	}
+	x1 := int(math.Round(a.x))
+	x2 := int(math.Round(b.x))
+	intersection := a.y + gradient*(float64(x1)-a.x)
+	for x := x1; x <= x2; x++ {
+		y := math.Floor(intersection)
+		f := intersection - y
+		set(x, int(y), 1-f)
+		set(x, int(y)+1, f)
+		intersection += gradient
+	}
+}
+
+//rasterizePolyline calls line for every segment of the polyline. A single point is drawn as a line of length zero
+func rasterizePolyline(points []image.Point, line func(x1, y1, x2, y2 int)) {
+	if len(points) == 1 {
+		line(points[0].X, points[0].Y, points[0].X, points[0].Y)
+	}
+	for i := 1; i < len(points); i++ {
+		line(points[i-1].X, points[i-1].Y, points[i].X, points[i].Y)
+	}
+}
+
+//rasterizePolygon calls span for every horizontal run [x1,x2) of pixels in row y whose centers lie inside the polygon
+func rasterizePolygon(points []image.Point, span func(x1, x2, y int)) {
+	if len(points) < 3 {
+		return
+	}
+	minY, maxY := points[0].Y, points[0].Y
+	for _, p := range points {
+		minY = min(minY, p.Y)
+		maxY = max(maxY, p.Y)
+	}
+	crossings := make([]float64, 0, len(points))
+	for y := minY; y < maxY; y++ {
+		cy := float64(y) + 0.5
+		crossings = crossings[:0]
+		for i := range points {
+			a := points[i]
+			b := points[(i+1)%len(points)]
+			if (float64(a.Y) <= cy) == (float64(b.Y) <= cy) {
+				continue
+			}
+			t := (cy - float64(a.Y)) / float64(b.Y-a.Y)
+			crossings = append(crossings, float64(a.X)+t*float64(b.X-a.X))
+		}
+		sort.Float64s(crossings)
+		for i := 0; i+1 < len(crossings); i += 2 {
+			x1 := int(math.Ceil(crossings[i] - 0.5))
+			x2 := int(math.Ceil(crossings[i+1] - 0.5))
+			if x2 > x1 {
+				span(x1, x2, y)
+			}
+		}
+	}
+}
+
+//lineRect returns the rectangle covered by a horizontal or vertical line including both end points
+func lineRect(x1, y1, x2, y2 int) image.Rectangle {
+	return image.Rect(min(x1, x2), min(y1, y2), max(x1, x2)+1, max(y1, y2)+1)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
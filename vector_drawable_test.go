@@ -0,0 +1,71 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+//pixelDrawable is a pixel-only Drawable recording all set pixels
+type pixelDrawable struct {
+	pixels map[image.Point]color.Color
+}
+
+func (s *pixelDrawable) Set(x, y int, c color.Color) {
+	s.pixels[image.Pt(x, y)] = c
+}
+
+func (s *pixelDrawable) DrawString(x, y int, text string, c color.Color) {
+}
+
+func TestVectorDrawableAdapter(t *testing.T) {
+	pixels := &pixelDrawable{pixels: make(map[image.Point]color.Color)}
+	v := NewVectorDrawable(pixels)
+
+	v.DrawLine(0, 0, 9, 3, yellow)
+	if len(pixels.pixels) != 10 {
+		t.Errorf("expected a line of 10 pixels, got %d", len(pixels.pixels))
+	}
+	for _, p := range []image.Point{{X: 0, Y: 0}, {X: 9, Y: 3}} {
+		if _, ok := pixels.pixels[p]; !ok {
+			t.Errorf("expected line to include end point %v", p)
+		}
+	}
+
+	pixels.pixels = make(map[image.Point]color.Color)
+	v.FillPolygon([]image.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}}, yellow)
+	if len(pixels.pixels) != 16 {
+		t.Errorf("expected a filled square of 16 pixels, got %d", len(pixels.pixels))
+	}
+}
+
+func TestImageDrawableIsVectorDrawable(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	d := NewImageDrawable(img)
+	if NewVectorDrawable(d) != VectorDrawable(d) {
+		t.Fatal("expected ImageDrawable to be used without adapter")
+	}
+	d.FillRect(image.Rect(2, 2, 5, 5), yellow)
+	d.DrawLine(0, 9, 9, 9, red)
+	if img.RGBAAt(4, 4) != yellow || img.RGBAAt(5, 5) == yellow {
+		t.Error("expected FillRect to exclude r.Max")
+	}
+	if img.RGBAAt(0, 9) != red || img.RGBAAt(9, 9) != red {
+		t.Error("expected DrawLine to include both end points")
+	}
+}
+
+func TestRasterizeSmoothLineCoverage(t *testing.T) {
+	columns := make(map[int]float64)
+	rasterizeSmoothLine(vector{x: 0, y: 0.25}, vector{x: 10, y: 3.75}, func(x, y int, coverage float64) {
+		columns[x] += coverage
+	})
+	if len(columns) != 11 {
+		t.Errorf("expected 11 columns, got %d", len(columns))
+	}
+	for x, c := range columns {
+		if c < 0.999 || c > 1.001 {
+			t.Errorf("expected a coverage of 1 in column %d, got %f", x, c)
+		}
+	}
+}
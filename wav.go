@@ -0,0 +1,269 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+//ErrInvalidWav is returned if a WAV file is malformed
+var ErrInvalidWav = errors.New("invalid wav")
+
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+	//wavMaxFormatSize is the size of the largest fmt chunk, which is the one of extensible WAV files
+	wavMaxFormatSize = 40
+)
+
+//Wav contains the decoded samples of a WAV file. All samples are normalized to [-1,1]
+type Wav struct {
+	sampleRate int
+	channels   [][]float64
+}
+
+//wavFormat is the content of the fmt chunk of a WAV file
+type wavFormat struct {
+	audioFormat   uint16
+	channels      uint16
+	sampleRate    uint32
+	byteRate      uint32
+	blockAlign    uint16
+	bitsPerSample uint16
+}
+
+//LoadWav reads the WAV file at path, see ReadWav
+func LoadWav(path string) (*Wav, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadWav(f)
+}
+
+//ReadWav decodes a WAV file with 8, 16, 24 or 32 bit integer or 32 or 64 bit float samples and any number of channels
+func ReadWav(r io.Reader) (*Wav, error) {
+	br := bufio.NewReader(r)
+	var header [12]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return nil, fmt.Errorf("reading header: %w", ErrInvalidWav)
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("no RIFF WAVE header: %w", ErrInvalidWav)
+	}
+	var format *wavFormat
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(br, chunk[:]); err != nil {
+			return nil, fmt.Errorf("no data chunk: %w", ErrInvalidWav)
+		}
+		id := string(chunk[0:4])
+		size := binary.LittleEndian.Uint32(chunk[4:8])
+		switch id {
+		case "fmt ":
+			f, err := readWavFormat(br, size)
+			if err != nil {
+				return nil, err
+			}
+			format = f
+		case "data":
+			if format == nil {
+				return nil, fmt.Errorf("data chunk before fmt chunk: %w", ErrInvalidWav)
+			}
+			return readWavData(br, format, size)
+		default:
+			//Chunks are padded to an even size. The padding is added in 64 bits, so it can't wrap around
+			if _, err := io.CopyN(io.Discard, br, int64(size)+int64(size%2)); err != nil {
+				return nil, fmt.Errorf("chunk %q: %w", id, ErrInvalidWav)
+			}
+		}
+	}
+}
+
+//readWavFormat reads the fmt chunk and resolves the format of extensible WAV files
+func readWavFormat(r *bufio.Reader, size uint32) (*wavFormat, error) {
+	if size < 16 || size > wavMaxFormatSize {
+		return nil, fmt.Errorf("fmt chunk of %d bytes: %w", size, ErrInvalidWav)
+	}
+	data := make([]byte, size+size%2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("fmt chunk: %w", ErrInvalidWav)
+	}
+	f := &wavFormat{
+		audioFormat:   binary.LittleEndian.Uint16(data[0:2]),
+		channels:      binary.LittleEndian.Uint16(data[2:4]),
+		sampleRate:    binary.LittleEndian.Uint32(data[4:8]),
+		byteRate:      binary.LittleEndian.Uint32(data[8:12]),
+		blockAlign:    binary.LittleEndian.Uint16(data[12:14]),
+		bitsPerSample: binary.LittleEndian.Uint16(data[14:16]),
+	}
+	if f.audioFormat == wavFormatExtensible {
+		if size < 26 {
+			return nil, fmt.Errorf("extensible fmt chunk of %d bytes: %w", size, ErrInvalidWav)
+		}
+		//The sub format GUID starts with the actual format
+		f.audioFormat = binary.LittleEndian.Uint16(data[24:26])
+	}
+	if f.channels == 0 || f.sampleRate == 0 {
+		return nil, fmt.Errorf("%d channels at %dHz: %w", f.channels, f.sampleRate, ErrInvalidWav)
+	}
+	return f, nil
+}
+
+//readWavData decodes the samples of the data chunk. A size of 0 or 0xFFFFFFFF, as written by some streaming
+//encoders, reads until the end of the file
+func readWavData(r io.Reader, f *wavFormat, size uint32) (*Wav, error) {
+	bytesPerSample := int(f.bitsPerSample+7) / 8
+	decode, err := wavSampleDecoder(f.audioFormat, f.bitsPerSample)
+	if err != nil {
+		return nil, err
+	}
+	if size != 0 && size != math.MaxUint32 {
+		r = io.LimitReader(r, int64(size))
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	frameSize := bytesPerSample * int(f.channels)
+	if int(f.blockAlign) > frameSize {
+		frameSize = int(f.blockAlign)
+	}
+	frames := len(data) / frameSize
+	w := &Wav{
+		sampleRate: int(f.sampleRate),
+		channels:   make([][]float64, f.channels),
+	}
+	for c := range w.channels {
+		w.channels[c] = make([]float64, frames)
+	}
+	for i := 0; i < frames; i++ {
+		for c := range w.channels {
+			offset := i*frameSize + c*bytesPerSample
+			w.channels[c][i] = decode(data[offset : offset+bytesPerSample])
+		}
+	}
+	return w, nil
+}
+
+//wavSampleDecoder returns a function decoding one little endian sample to [-1,1]
+func wavSampleDecoder(audioFormat uint16, bits uint16) (func(b []byte) float64, error) {
+	switch {
+	case audioFormat == wavFormatPCM && bits == 8:
+		return func(b []byte) float64 {
+			return (float64(b[0]) - 128) / 128
+		}, nil
+	case audioFormat == wavFormatPCM && bits == 16:
+		return func(b []byte) float64 {
+			return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+		}, nil
+	case audioFormat == wavFormatPCM && bits == 24:
+		return func(b []byte) float64 {
+			v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+			return float64(v) / (1 << 23)
+		}, nil
+	case audioFormat == wavFormatPCM && bits == 32:
+		return func(b []byte) float64 {
+			return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+		}, nil
+	case audioFormat == wavFormatFloat && bits == 32:
+		return func(b []byte) float64 {
+			return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		}, nil
+	case audioFormat == wavFormatFloat && bits == 64:
+		return func(b []byte) float64 {
+			return math.Float64frombits(binary.LittleEndian.Uint64(b))
+		}, nil
+	}
+	return nil, fmt.Errorf("wav format %d with %d bits: %w", audioFormat, bits, ErrUnsupportedFormat)
+}
+
+//SampleRate returns the sample rate in Hz
+func (s *Wav) SampleRate() int {
+	return s.sampleRate
+}
+
+//Channels returns the samples of every channel
+func (s *Wav) Channels() [][]float64 {
+	return s.channels
+}
+
+//Duration returns the length of the recording
+func (s *Wav) Duration() time.Duration {
+	if len(s.channels) == 0 {
+		return 0
+	}
+	return time.Duration(len(s.channels[0])) * time.Second / time.Duration(s.sampleRate)
+}
+
+//Times returns the time of every sample, ready for NewWaveDrawer
+func (s *Wav) Times() []time.Duration {
+	if len(s.channels) == 0 {
+		return make([]time.Duration, 0)
+	}
+	times := make([]time.Duration, len(s.channels[0]))
+	for i := range times {
+		times[i] = time.Duration(int64(i) * int64(time.Second) / int64(s.sampleRate))
+	}
+	return times
+}
+
+//WaveItems returns one item per channel, each with a distinct color and named after the channel
+func (s *Wav) WaveItems() []*WaveDrawerItems {
+	items := make([]*WaveDrawerItems, len(s.channels))
+	for i, c := range s.channels {
+		items[i] = NewWaveDrawerItems(c, channelColors[i%len(channelColors)]).Name(s.channelName(i))
+	}
+	return items
+}
+
+//channelName returns "left" and "right" for stereo recordings, "channel 1" and so on for more channels and no name
+//for mono recordings, which don't need a legend
+func (s *Wav) channelName(channel int) string {
+	switch len(s.channels) {
+	case 1:
+		return ""
+	case 2:
+		return [2]string{"left", "right"}[channel]
+	}
+	return fmt.Sprintf("channel %d", channel+1)
+}
+
+//NewWaveDrawerFromWav is a constructor for WaveDrawer showing all channels of wav, colored by the palette of the theme
+func NewWaveDrawerFromWav(drawer *DrawerBuilder, wav *Wav, title string) *WaveDrawer {
+	w := NewWaveDrawer(drawer, wav.Times(), title)
+	for i, c := range wav.channels {
+		w.SetItems(NewWaveDrawerItems(c, nil).Name(wav.channelName(i)))
+	}
+	return w
+}
+
+//NewSpectrumDrawerFromWav is a constructor for SpectrumDrawer showing the magnitudes of all channels of wav. If
+//analysis is nil, NewSpectrumAnalysis is used
+func NewSpectrumDrawerFromWav(drawer *DrawerBuilder, wav *Wav, analysis *SpectrumAnalysis, title string) (*SpectrumDrawer, error) {
+	if analysis == nil {
+		analysis = NewSpectrumAnalysis()
+	}
+	var spectrum *SpectrumDrawer
+	for i, c := range wav.channels {
+		result, err := analysis.Analyze(c, float64(wav.sampleRate))
+		if err != nil {
+			return nil, fmt.Errorf("channel %d: %w", i, err)
+		}
+		if spectrum == nil {
+			spectrum = NewSpectrumDrawer(drawer, result.frequencies, title)
+		}
+		spectrum.SetItems(result.MagnitudeItems(nil).Name(wav.channelName(i)))
+	}
+	if spectrum == nil {
+		return nil, fmt.Errorf("no channels: %w", ErrEmptyData)
+	}
+	return spectrum, nil
+}
@@ -0,0 +1,112 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+//newTestWav encodes frames of samples as WAV file
+func newTestWav(audioFormat uint16, bits uint16, sampleRate uint32, frames [][]float64) []byte {
+	channels := uint16(len(frames[0]))
+	blockAlign := channels * bits / 8
+	data := new(bytes.Buffer)
+	for _, frame := range frames {
+		for _, v := range frame {
+			switch {
+			case audioFormat == wavFormatFloat:
+				binary.Write(data, binary.LittleEndian, float32(v))
+			case bits == 16:
+				binary.Write(data, binary.LittleEndian, int16(v*(1<<15-1)))
+			case bits == 24:
+				i := int32(v * (1<<23 - 1))
+				data.Write([]byte{byte(i), byte(i >> 8), byte(i >> 16)})
+			}
+		}
+	}
+	b := new(bytes.Buffer)
+	b.WriteString("RIFF")
+	binary.Write(b, binary.LittleEndian, uint32(36+data.Len()))
+	b.WriteString("WAVEfmt ")
+	for _, v := range []interface{}{uint32(16), audioFormat, channels, sampleRate, sampleRate * uint32(blockAlign), blockAlign, bits} {
+		binary.Write(b, binary.LittleEndian, v)
+	}
+	b.WriteString("data")
+	binary.Write(b, binary.LittleEndian, uint32(data.Len()))
+	b.Write(data.Bytes())
+	return b.Bytes()
+}
+
+func TestReadWav(t *testing.T) {
+	frames := [][]float64{{0, 0.5}, {0.25, -0.5}, {-1, 1}}
+	for _, c := range []struct {
+		audioFormat uint16
+		bits        uint16
+	}{{wavFormatPCM, 16}, {wavFormatPCM, 24}, {wavFormatFloat, 32}} {
+		wav, err := ReadWav(bytes.NewReader(newTestWav(c.audioFormat, c.bits, 48000, frames)))
+		if err != nil {
+			t.Fatalf("format %d with %d bits: %v", c.audioFormat, c.bits, err)
+		}
+		if wav.SampleRate() != 48000 || len(wav.Channels()) != 2 || len(wav.Channels()[0]) != 3 {
+			t.Fatalf("format %d with %d bits: unexpected layout", c.audioFormat, c.bits)
+		}
+		for i, frame := range frames {
+			for ch, want := range frame {
+				if got := wav.Channels()[ch][i]; math.Abs(got-want) > 1e-4 {
+					t.Errorf("format %d with %d bits: sample %d of channel %d is %f instead of %f", c.audioFormat, c.bits, i, ch, got, want)
+				}
+			}
+		}
+		if times := wav.Times(); len(times) != 3 || times[2].Microseconds() != 41 {
+			t.Errorf("unexpected times %v", times)
+		}
+		if items := wav.WaveItems(); len(items) != 2 || items[0].color == items[1].color {
+			t.Error("expected one item with a distinct color per channel")
+		}
+		if spectrum, err := NewSpectrumDrawerFromWav(NewDrawer(), wav, nil, ""); err != nil || len(spectrum.items) != 2 {
+			t.Errorf("expected one spectrum item per channel, got error %v", err)
+		}
+	}
+	if _, err := ReadWav(bytes.NewReader([]byte("RIFF0000AVI "))); !errors.Is(err, ErrInvalidWav) {
+		t.Errorf("expected ErrInvalidWav, got %v", err)
+	}
+}
+
+func TestReadWavChunkSizes(t *testing.T) {
+	valid := newTestWav(wavFormatPCM, 16, 48000, [][]float64{{0.5}, {-0.5}})
+	//withChunk inserts a chunk with the given header size and content after the RIFF header
+	withChunk := func(id string, size uint32, content []byte) []byte {
+		b := new(bytes.Buffer)
+		b.Write(valid[:12])
+		b.WriteString(id)
+		binary.Write(b, binary.LittleEndian, size)
+		b.Write(content)
+		b.Write(valid[12:])
+		return b.Bytes()
+	}
+
+	//An odd sized chunk is followed by a padding byte
+	wav, err := ReadWav(bytes.NewReader(withChunk("LIST", 3, []byte{1, 2, 3, 0})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wav.Channels()) != 1 || len(wav.Channels()[0]) != 2 {
+		t.Errorf("unexpected layout after an odd sized chunk")
+	}
+
+	tests := []struct {
+		name string
+		wav  []byte
+	}{
+		{name: "oversized fmt chunk", wav: withChunk("fmt ", math.MaxUint32, make([]byte, 16))},
+		{name: "large fmt chunk", wav: withChunk("fmt ", 1<<20, make([]byte, 16))},
+		{name: "oversized unknown chunk", wav: withChunk("LIST", math.MaxUint32, nil)},
+	}
+	for _, test := range tests {
+		if _, err := ReadWav(bytes.NewReader(test.wav)); !errors.Is(err, ErrInvalidWav) {
+			t.Errorf("%s: expected ErrInvalidWav, got %v", test.name, err)
+		}
+	}
+}
@@ -1,10 +1,10 @@
 package go_hugipipes_signal_drawer
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"image/color"
-	"math"
 	"time"
 )
 
@@ -13,52 +13,90 @@ import (
 type WaveDrawerItems struct {
 	points []float64
 	color  color.Color
+	style  WaveDrawStyle
+	name   string
 }
 
+//WaveDrawStyle defines how the points of WaveDrawerItems are drawn
+type WaveDrawStyle int
+
+const (
+	//WaveDrawStyleDots draws every point as a single pixel. This is the default
+	WaveDrawStyleDots WaveDrawStyle = iota
+	//WaveDrawStyleLines connects consecutive points with lines
+	WaveDrawStyleLines
+	//WaveDrawStyleSmoothLines connects consecutive points with anti-aliased lines, blended with the background-color
+	WaveDrawStyleSmoothLines
+)
+
 //NewWaveDrawerItems is the constructor for WaveDrawerItems
 //points are all the data-points. It will be automatically scaled to the plot
-//color is the color the plot should have
+//color is the color the plot should have, nil uses the palette of the theme
 func NewWaveDrawerItems(points []float64, color color.Color) *WaveDrawerItems {
 	return &WaveDrawerItems{
-		points,
-		color,
+		points: points,
+		color:  color,
+		style:  WaveDrawStyleDots,
 	}
 }
 
+//Style sets how the points are drawn. Default is WaveDrawStyleDots
+func (s *WaveDrawerItems) Style(style WaveDrawStyle) *WaveDrawerItems {
+	s.style = style
+	return s
+}
+
+//Name sets the name of the items shown in the legend. Default is no name, which hides the items in the legend
+func (s *WaveDrawerItems) Name(name string) *WaveDrawerItems {
+	s.name = name
+	return s
+}
+
 //WaveDrawer is a widget that can be used in drawer to draw a time-based wave signal
 type WaveDrawer struct {
 	*DrawerBuilder
-	cache           *waveDrawerCache
-	title           string
-	times           []time.Duration
-	items           []WaveDrawerItems
-	backgroundColor color.Color
-	dividerColor    color.Color
-	axisColor       color.Color
-	titleColor      color.Color
-	startTime       time.Duration
-	endTime         time.Duration
+	size           widgetSize
+	cache          *waveDrawerCache
+	title          string
+	times          []time.Duration
+	items          []WaveDrawerItems
+	colors         widgetTheme
+	startTime      time.Duration
+	endTime        time.Duration
+	yUnit          string
+	yFormatter     func(value float64) string
+	decimation     WaveDecimation
+	envelopeRMS    bool
+	legendPosition LegendPosition
 }
 
 //NewWaveDrawer is the constructor for WaveDrawer
 func NewWaveDrawer(drawer *DrawerBuilder, times []time.Duration, title string) *WaveDrawer {
-	return &WaveDrawer{
-		DrawerBuilder:   drawer,
-		title:           title,
-		times:           times,
-		backgroundColor: image.Black.C,
-		axisColor:       image.White.C,
-		titleColor:      image.White.C,
-		items:           make([]WaveDrawerItems, 0),
-		dividerColor:    gray,
-		startTime:       times[0],
-		endTime:         times[len(times)-1],
+	s := &WaveDrawer{
+		DrawerBuilder: drawer,
+		title:         title,
+		times:         times,
+		items:         make([]WaveDrawerItems, 0),
+		decimation:    WaveDecimationAuto,
+	}
+	if len(times) > 0 {
+		s.startTime = times[0]
+		s.endTime = times[len(times)-1]
 	}
+	return s
 }
 
 //waveDrawerCache contains data that would be recalculated often during drawing
 type waveDrawerCache struct {
+	ctx              context.Context
+	x                int
+	theme            Theme
+	canvas           VectorDrawable
+	margins          margins
+	plotWidth        int
+	plotHeight       int
 	timeFactor       float64
+	valueRange       yRange
 	calculatedWidth  int
 	calculatedHeight int
 }
@@ -69,27 +107,85 @@ func (s *WaveDrawer) SetItems(items *WaveDrawerItems) *WaveDrawer {
 	return s
 }
 
-//BackgroundColor sets the background-color of the plot. Default is black
+//Theme sets the colors of this plot, replacing the theme of the DrawerBuilder. Colors set with the color setters
+//still override the theme
+func (s *WaveDrawer) Theme(theme Theme) *WaveDrawer {
+	s.colors.theme = &theme
+	return s
+}
+
+//BackgroundColor sets the background-color of the plot. Default is the background of the theme
 func (s *WaveDrawer) BackgroundColor(backgroundColor color.Color) *WaveDrawer {
-	s.backgroundColor = backgroundColor
+	s.colors.background = backgroundColor
 	return s
 }
 
-//DividerColor sets the divider-color of the plot. Default is gray
+//DividerColor sets the divider-color of the plot. Default is the divider color of the theme
 func (s *WaveDrawer) DividerColor(dividerColor color.Color) *WaveDrawer {
-	s.dividerColor = dividerColor
+	s.colors.divider = dividerColor
 	return s
 }
 
-//AxisColor sets the color of the axis. Default is white
+//AxisColor sets the color of the axis. Default is the axis color of the theme
 func (s *WaveDrawer) AxisColor(axisColor color.Color) *WaveDrawer {
-	s.axisColor = axisColor
+	s.colors.axis = axisColor
 	return s
 }
 
-//TitleColor sets the color of the title of the Spectrum.
+//TitleColor sets the color of the title. Default is the title color of the theme
 func (s *WaveDrawer) TitleColor(titleColor color.Color) *WaveDrawer {
-	s.titleColor = titleColor
+	s.colors.title = titleColor
+	return s
+}
+
+//Width sets the width of the plot without margins. Default is the plot width of the DrawerBuilder
+func (s *WaveDrawer) Width(width int) *WaveDrawer {
+	s.size.width = max(width, 0)
+	return s
+}
+
+//Height sets the height of the plot without margins. Default is the plot height of the DrawerBuilder
+func (s *WaveDrawer) Height(height int) *WaveDrawer {
+	s.size.height = max(height, 0)
+	return s
+}
+
+//Margins sets the space around the plot for the title and the axis labels. Default is the space measured to fit the
+//text, but at least the label space of the DrawerBuilder on all sides
+func (s *WaveDrawer) Margins(top int, right int, bottom int, left int) *WaveDrawer {
+	s.size.setMargins(top, right, bottom, left)
+	return s
+}
+
+//Legend sets where the legend with the names of the items is drawn. Default is LegendTopRight
+func (s *WaveDrawer) Legend(position LegendPosition) *WaveDrawer {
+	s.legendPosition = position
+	return s
+}
+
+//YUnit sets the unit appended to the value labels of the y-axis. Default is no unit
+func (s *WaveDrawer) YUnit(yUnit string) *WaveDrawer {
+	s.yUnit = yUnit
+	return s
+}
+
+//YFormatter sets a function formatting the value labels of the y-axis. It replaces the default formatting with YUnit
+func (s *WaveDrawer) YFormatter(yFormatter func(value float64) string) *WaveDrawer {
+	s.yFormatter = yFormatter
+	return s
+}
+
+//Decimation sets if the items are reduced to a min/max envelope per pixel column before drawing, which shows long
+//signals faithfully and draws them much faster. The style of the items is ignored for envelopes. Default is
+//WaveDecimationAuto
+func (s *WaveDrawer) Decimation(decimation WaveDecimation) *WaveDrawer {
+	s.decimation = decimation
+	return s
+}
+
+//EnvelopeRMS sets if the RMS of every pixel column is drawn inside the min/max envelope. Default is false
+func (s *WaveDrawer) EnvelopeRMS(envelopeRMS bool) *WaveDrawer {
+	s.envelopeRMS = envelopeRMS
 	return s
 }
 
@@ -111,48 +207,50 @@ func (s *WaveDrawer) EndTime(endTime time.Duration) *WaveDrawer {
 	return s
 }
 
-//newSpectrumDrawerCache creates a new cache with pre-calculated values for plotting to avoid executing the same operation multiple times
-func (s *WaveDrawer) newWaveDrawerCache() *waveDrawerCache {
+//newWaveDrawerCache creates a new cache with pre-calculated values for plotting to the region bounds to avoid
+//executing the same operation multiple times
+func (s *WaveDrawer) newWaveDrawerCache(bounds image.Rectangle) *waveDrawerCache {
+	m := s.plotMargins()
+	plotWidth := bounds.Dx() - m.left - m.right
 	return &waveDrawerCache{
-		timeFactor:       float64(s.plotWidth) / float64(s.endTime.Nanoseconds()-s.startTime.Nanoseconds()),
-		calculatedWidth:  s.plotWidth + 2*s.labelSpace,
-		calculatedHeight: s.plotHeight + 2*s.labelSpace,
+		x:                bounds.Min.X,
+		theme:            s.colors.resolve(s.DrawerBuilder),
+		margins:          m,
+		plotWidth:        plotWidth,
+		plotHeight:       bounds.Dy() - m.top - m.bottom,
+		timeFactor:       float64(plotWidth) / float64(s.endTime.Nanoseconds()-s.startTime.Nanoseconds()),
+		calculatedWidth:  bounds.Dx(),
+		calculatedHeight: bounds.Dy(),
 	}
 }
 
 //freqToX recalculates a frequency to the x-coordinates
 func (s *WaveDrawer) timeToX(time time.Duration) int {
-	if time.Milliseconds() < s.startTime.Milliseconds() || time.Milliseconds() > s.endTime.Milliseconds() {
+	if time < s.startTime || time > s.endTime {
 		return -1000
 	}
 	t := time - s.startTime
-	return int(float64(t.Nanoseconds())*s.cache.timeFactor) + s.labelSpace
+	return int(float64(t.Nanoseconds())*s.cache.timeFactor) + s.cache.x + s.cache.margins.left
 }
 
 //drawBackground plots the background
 func (s *WaveDrawer) drawBackground(y int) {
 	top := y
 	bottom := top + s.cache.calculatedHeight
-	for x := 0; x <= s.cache.calculatedWidth; x++ {
-		for y := top; y <= bottom; y++ {
-			s.drawable.Set(x, y, s.backgroundColor)
-		}
-	}
+	s.cache.canvas.FillRect(image.Rect(s.cache.x, top, s.cache.x+s.cache.calculatedWidth, bottom), s.cache.theme.Background)
 }
 
 //drawXAxis draws the x-axis of the plot
 func (s *WaveDrawer) drawXAxis(y int) {
-	y += s.labelSpace + (s.plotHeight / 2)
-	maxX := s.cache.calculatedWidth - s.labelSpace
-	for x := s.labelSpace - s.spacePart; x <= maxX; x++ {
-		s.drawable.Set(x, y, s.axisColor)
-	}
-	y += s.plotHeight / 2
+	y += s.cache.margins.top + (s.cache.plotHeight / 2)
+	maxX := s.cache.x + s.cache.calculatedWidth - s.cache.margins.right
+	s.cache.canvas.DrawLine(s.cache.x+s.cache.margins.left-s.spacePart, y, maxX, y, s.cache.theme.Axis)
+	y += s.cache.plotHeight / 2
 	dt := s.endTime - s.startTime
 	dt = dt / 5
 	tt := s.startTime
 
-	for tt.Milliseconds() <= s.endTime.Milliseconds() {
+	for tt <= s.endTime {
 		s.drawTime(tt, y)
 		tt += dt
 	}
@@ -162,91 +260,214 @@ func (s *WaveDrawer) drawXAxis(y int) {
 func (s *WaveDrawer) drawTime(t time.Duration, lineY int) {
 	x := s.timeToX(t)
 	bottom := lineY + s.spacePart*3
-	for y := lineY; y <= bottom; y++ {
-		s.drawable.Set(x, y, s.axisColor)
-	}
-	s.drawable.DrawString(x, bottom+s.spacePart*2, fmt.Sprintf("%dms", t.Milliseconds()), s.axisColor)
+	s.cache.canvas.DrawLine(x, lineY, x, bottom, s.cache.theme.Axis)
+	label := timeLabel(t)
+	style := s.tickStyle()
+	s.cache.canvas.DrawText(x-style.width(label)/2, bottom+s.spacePart/2+style.ascent(), label, style, s.cache.theme.Axis)
 }
 
-//draw draws all content to the drawable
-func (s *WaveDrawer) draw(y int) {
-	s.cache = s.newWaveDrawerCache()
+//Draw implements DrawerWidget interface
+func (s *WaveDrawer) Draw(region image.Rectangle, canvas VectorDrawable) error {
+	return s.DrawContext(context.Background(), region, canvas)
+}
+
+//DrawContext implements ContextDrawerWidget interface
+func (s *WaveDrawer) DrawContext(ctx context.Context, region image.Rectangle, canvas VectorDrawable) error {
+	y := region.Min.Y
+	s.cache = s.newWaveDrawerCache(region)
+	s.cache.ctx = ctx
+	s.cache.canvas = canvas
+	s.cache.valueRange = s.valueRange()
 	s.drawBackground(y)
-	s.drawPlotTitle(s.title, s.spacePart*3+y)
-	for _, item := range s.items {
+	s.drawPlotTitle(s.title, y)
+	for i, item := range s.items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		item.color = itemColor(item.color, s.cache.theme, i)
 		s.drawItem(item, y)
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	s.drawXAxis(y)
 	s.drawYAxis(y)
-	if y > 0 {
-		s.drawDivider(y)
-	}
+	s.legend().draw(s.cache.canvas, s.plotBounds(y))
+	s.drawDivider(y)
+	return nil
+}
 
+//plotBounds returns the region of the plot without margins
+func (s *WaveDrawer) plotBounds(y int) image.Rectangle {
+	x := s.cache.x + s.cache.margins.left
+	y += s.cache.margins.top
+	return image.Rect(x, y, x+s.cache.plotWidth, y+s.cache.plotHeight)
 }
 
-//drawItem draws the plot-points of a points set to the wave
-func (s *WaveDrawer) drawItem(item WaveDrawerItems, y int) {
-	maxValue := item.points[0]
-	minValue := item.points[0]
-	for _, v := range item.points {
-		maxValue = math.Max(maxValue, v)
-		minValue = math.Min(minValue, v)
+//legend returns the legend of all named items
+func (s *WaveDrawer) legend() legend {
+	theme := s.colors.resolve(s.DrawerBuilder)
+	entries := make([]legendEntry, 0)
+	for i, item := range s.items {
+		if item.name != "" {
+			entries = append(entries, legendEntry{name: item.name, color: itemColor(item.color, theme, i)})
+		}
 	}
-	offset := -minValue
-	maxValue += offset
-
-	factor := float64(s.plotHeight) / maxValue
+	return legend{
+		position:   s.legendPosition,
+		entries:    entries,
+		style:      s.tickStyle(),
+		padding:    s.spacePart,
+		background: theme.Background,
+		border:     theme.Divider,
+		textColor:  theme.Axis,
+	}
+}
 
-	bottom := y + s.labelSpace + s.plotHeight
+//drawItem draws the plot-points of a points set to the wave according to the style of the item or as envelope
+//according to the decimation
+func (s *WaveDrawer) drawItem(item WaveDrawerItems, y int) {
+	if s.decimation != WaveDecimationOff {
+		envelope, err := newWaveEnvelope(s.cache.ctx, s.times, item.points, s.startTime, s.endTime, s.cache.plotWidth)
+		if err != nil {
+			return
+		}
+		if s.decimation == WaveDecimationAlways || envelope.samplesPerColumn() > 1 {
+			s.drawEnvelope(item, envelope, y)
+			return
+		}
+	}
+	yRange := s.cache.valueRange
+	bottom := y + s.cache.margins.top + s.cache.plotHeight
+	line := make([]image.Point, 0)
+	smoothLine := make([]vector, 0)
 	for i, it := range item.points {
+		if i%cancelCheckInterval == 0 && s.cache.ctx.Err() != nil {
+			return
+		}
 		t := s.times[i]
-		if t.Milliseconds() >= s.startTime.Milliseconds() && t.Milliseconds() <= s.endTime.Milliseconds() {
-			//it := item.points[i]
+		if t >= s.startTime && t <= s.endTime {
 			x := s.timeToX(t)
 			if x > 0 {
-				yPoint := it + offset
-				yPoint = yPoint * factor
-				YPoint := bottom - int(yPoint)
-				s.drawable.Set(x, YPoint, item.color)
+				yPoint := yRange.toY(it, bottom, s.cache.plotHeight)
+				switch item.style {
+				case WaveDrawStyleLines:
+					line = append(line, image.Pt(x, yPoint))
+				case WaveDrawStyleSmoothLines:
+					smoothLine = append(smoothLine, vector{
+						x: float64(t-s.startTime)*s.cache.timeFactor + float64(s.cache.x+s.cache.margins.left),
+						y: float64(bottom) - yRange.fraction(yRange.value(it))*float64(s.cache.plotHeight),
+					})
+				default:
+					s.cache.canvas.Set(x, yPoint, item.color)
+				}
 			}
 		}
 	}
+	s.cache.canvas.DrawPolyline(line, item.color)
+	for i := 1; i < len(smoothLine); i++ {
+		rasterizeSmoothLine(smoothLine[i-1], smoothLine[i], func(x, y int, coverage float64) {
+			s.cache.canvas.Set(x, y, blend(s.cache.theme.Background, item.color, coverage))
+		})
+	}
 }
 
-//drawDivider draws a horizontal line a the end of the plot
+//drawDivider draws a line at the top of the plot if it isn't in the first row and at the left if it isn't in the
+//first column
 func (s *WaveDrawer) drawDivider(y int) {
-	for x := 0; x <= s.cache.calculatedWidth; x++ {
-		s.drawable.Set(x, y, s.dividerColor)
+	x := s.cache.x
+	if y > 0 {
+		s.cache.canvas.DrawLine(x, y, x+s.cache.calculatedWidth-1, y, s.cache.theme.Divider)
+	}
+	if x > 0 {
+		s.cache.canvas.DrawLine(x, y, x, y+s.cache.calculatedHeight-1, s.cache.theme.Divider)
+	}
+}
+
+//Draws the plot title above the plot or at the top of the widget if the top margin is too small
+func (s *WaveDrawer) drawPlotTitle(title string, top int) {
+	style := s.titleStyle()
+	x := s.cache.x + s.cache.margins.left
+	y := max(top+s.cache.margins.top-s.spacePart-style.descent(), top+style.ascent())
+	s.cache.canvas.DrawText(x, y, title, style, s.cache.theme.Title)
+}
+
+//getTitle returns the title of the plot
+func (s *WaveDrawer) getTitle() string {
+	return s.title
+}
+
+//Validate checks the data of the plot. It is called before the plot is drawn
+func (s *WaveDrawer) Validate() error {
+	if len(s.times) == 0 {
+		return fmt.Errorf("no times: %w", ErrEmptyData)
+	}
+	if s.endTime <= s.startTime {
+		return fmt.Errorf("end time %v is not after start time %v: %w", s.endTime, s.startTime, ErrZeroRange)
 	}
+	for i, item := range s.items {
+		if err := validatePoints(fmt.Sprintf("item %d", i), item.points, len(s.times)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Measure implements DrawerWidget interface
+func (s *WaveDrawer) Measure() Measurement {
+	return s.size.measure(s.DrawerBuilder, s.plotMargins())
+}
+
+//plotMargins returns the margins around the plot
+func (s *WaveDrawer) plotMargins() margins {
+	return s.legend().margins(s.size.plotMargins(s.DrawerBuilder, s.textMargins()))
 }
 
-//Draws the plot title
-func (s *WaveDrawer) drawPlotTitle(title string, lineTop int) {
-	x := s.labelSpace
-	y := lineTop + 3*s.spacePart
-	s.drawable.DrawString(x, y, title, s.titleColor)
+//textMargins returns the margins fitting the title, the time labels and the value labels
+func (s *WaveDrawer) textMargins() margins {
+	m := s.timeAxisMargins(s.startTime, s.endTime)
+	m.top = s.titleMargin(s.title, s.titleStyle())
+	if len(s.items) > 0 {
+		m.left = max(m.left, s.yAxisTicks().width(s.valueRange())+s.spacePart)
+	}
+	return m
 }
 
-//getWidgetWidth implements Widget interface
-func (s *WaveDrawer) getWidgetWidth() int {
-	s.cache = s.newWaveDrawerCache()
-	return s.cache.calculatedWidth
+//valueRange returns the range between the minimum and maximum of all items, which all items are scaled to
+func (s *WaveDrawer) valueRange() yRange {
+	points := make([][]float64, len(s.items))
+	for i, item := range s.items {
+		points[i] = item.points
+	}
+	return pointsRange(points...)
 }
 
-//getWidgetHeight implements Widget interface
-func (s *WaveDrawer) getWidgetHeight() int {
-	s.cache = s.newWaveDrawerCache()
-	return s.cache.calculatedHeight
+//yAxisTicks returns the ticks of the y-axis for a plot of the height set in the DrawerBuilder or the widget
+func (s *WaveDrawer) yAxisTicks() yAxisTicks {
+	return yAxisTicks{
+		height:     s.size.plotHeight(s.DrawerBuilder),
+		tickLength: s.spacePart,
+		style:      s.tickStyle(),
+		unit:       s.yUnit,
+		formatter:  s.yFormatter,
+	}
 }
 
 //Draws the y axis
 func (s *WaveDrawer) drawYAxis(top int) {
-	top += s.labelSpace
-	bottom := top + s.plotHeight + s.spacePart
-	x := s.labelSpace
+	top += s.cache.margins.top
+	bottom := top + s.cache.plotHeight + s.spacePart
+	x := s.cache.x + s.cache.margins.left
+	s.cache.canvas.DrawLine(x, top, x, bottom, s.cache.theme.Axis)
 
-	for y := top; y <= bottom; y++ {
-		s.drawable.Set(x, y, s.axisColor)
+	if len(s.items) == 0 {
+		return
 	}
-
+	ticks := s.yAxisTicks()
+	ticks.canvas = s.cache.canvas
+	ticks.x = x
+	ticks.bottom = top + s.cache.plotHeight
+	ticks.height = s.cache.plotHeight
+	ticks.color = s.cache.theme.Axis
+	ticks.draw(s.cache.valueRange)
 }
@@ -0,0 +1,57 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestWaveDrawerSharedRange(t *testing.T) {
+	d := NewDrawer().PlotWidth(100).PlotHeight(100)
+	times := []time.Duration{0, time.Millisecond, 2 * time.Millisecond}
+	low := color.RGBA{R: 1, G: 254, B: 3, A: 255}
+	high := color.RGBA{R: 254, G: 1, B: 3, A: 255}
+	wave := NewWaveDrawer(d, times, "").Margins(10, 10, 10, 10).
+		SetItems(NewWaveDrawerItems([]float64{0, 1, 0}, low)).
+		SetItems(NewWaveDrawerItems([]float64{0, 3, 0}, high))
+	if r := wave.valueRange(); r.min != 0 || r.max != 3 {
+		t.Errorf("expected the axis to be labeled from 0 to 3, got %f to %f", r.min, r.max)
+	}
+	d.AddPlot(wave)
+	dr, err := d.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := dr.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	//Both items are scaled from 0 at y=110 to 3 at y=10, so the peak of 1 is at a third of the plot height
+	tests := []struct {
+		c    color.RGBA
+		want int
+	}{
+		{c: low, want: 77},
+		{c: high, want: 10},
+	}
+	for _, test := range tests {
+		top := topmostPixel(img.(*image.RGBA), test.c)
+		if top < test.want-1 || top > test.want+1 {
+			t.Errorf("expected the peak of %v at y=%d, got y=%d", test.c, test.want, top)
+		}
+	}
+}
+
+//topmostPixel returns the lowest y of all pixels of color c, or -1 if there are none
+func topmostPixel(img *image.RGBA, c color.RGBA) int {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if img.RGBAAt(x, y) == c {
+				return y
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,123 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+//WaveDecimation defines if long signals are reduced to an envelope per pixel column before drawing
+type WaveDecimation int
+
+const (
+	//WaveDecimationAuto draws the envelope of an item if there is more than one sample per pixel column. This is the
+	//default
+	WaveDecimationAuto WaveDecimation = iota
+	//WaveDecimationOff always draws every sample according to the style of the item
+	WaveDecimationOff
+	//WaveDecimationAlways always draws the envelope of an item
+	WaveDecimationAlways
+)
+
+//waveEnvelope contains the minimum, maximum and RMS of all samples within every pixel column of a plot
+type waveEnvelope struct {
+	columns []waveEnvelopeColumn
+}
+
+//waveEnvelopeColumn summarizes the samples within one pixel column
+type waveEnvelopeColumn struct {
+	count int
+	min   float64
+	max   float64
+	last  float64
+	sumSq float64
+}
+
+//rms returns the root mean square of all samples in the column
+func (s waveEnvelopeColumn) rms() float64 {
+	return math.Sqrt(s.sumSq / float64(s.count))
+}
+
+//add adds the sample v to the column
+func (s *waveEnvelopeColumn) add(v float64) {
+	if s.count == 0 {
+		s.min = v
+		s.max = v
+	}
+	s.count++
+	s.min = math.Min(s.min, v)
+	s.max = math.Max(s.max, v)
+	s.last = v
+	s.sumSq += v * v
+}
+
+//newWaveEnvelope reduces points at times within [startTime,endTime] to width+1 pixel columns in a single pass. It stops
+//and returns ctx.Err() soon after ctx is done
+func newWaveEnvelope(ctx context.Context, times []time.Duration, points []float64, startTime time.Duration, endTime time.Duration, width int) (*waveEnvelope, error) {
+	e := &waveEnvelope{columns: make([]waveEnvelopeColumn, width+1)}
+	if endTime <= startTime {
+		return e, nil
+	}
+	factor := float64(width) / float64(endTime-startTime)
+	for i := 0; i < len(points) && i < len(times); i++ {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		t := times[i]
+		v := points[i]
+		if t < startTime || t > endTime || math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		e.columns[int(float64(t-startTime)*factor)].add(v)
+	}
+	return e, nil
+}
+
+//samplesPerColumn returns the average number of samples in the columns containing samples
+func (s *waveEnvelope) samplesPerColumn() float64 {
+	samples := 0
+	columns := 0
+	for _, c := range s.columns {
+		if c.count > 0 {
+			samples += c.count
+			columns++
+		}
+	}
+	if columns == 0 {
+		return 0
+	}
+	return float64(samples) / float64(columns)
+}
+
+//drawEnvelope draws a vertical line from the minimum to the maximum of every pixel column. The lines are extended to
+//the last sample of the previous column, so the envelope has no gaps. If rms is set, the RMS is drawn as a band
+//around zero in the color of the item, while the envelope is drawn half-transparent
+func (s *WaveDrawer) drawEnvelope(item WaveDrawerItems, envelope *waveEnvelope, y int) {
+	yRange := s.cache.valueRange
+	bottom := y + s.cache.margins.top + s.cache.plotHeight
+	envelopeColor := item.color
+	if s.envelopeRMS {
+		envelopeColor = blend(s.cache.theme.Background, item.color, 0.5)
+	}
+	var previous *waveEnvelopeColumn
+	for i := range envelope.columns {
+		c := &envelope.columns[i]
+		if c.count == 0 {
+			continue
+		}
+		lo, hi := c.min, c.max
+		if previous != nil {
+			lo = math.Min(lo, previous.last)
+			hi = math.Max(hi, previous.last)
+		}
+		x := s.cache.x + s.cache.margins.left + i
+		s.cache.canvas.DrawLine(x, yRange.toY(hi, bottom, s.cache.plotHeight), x, yRange.toY(lo, bottom, s.cache.plotHeight), envelopeColor)
+		if s.envelopeRMS {
+			rms := c.rms()
+			s.cache.canvas.DrawLine(x, yRange.toY(math.Min(rms, c.max), bottom, s.cache.plotHeight), x, yRange.toY(math.Max(-rms, c.min), bottom, s.cache.plotHeight), item.color)
+		}
+		previous = c
+	}
+}
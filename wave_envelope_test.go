@@ -0,0 +1,31 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWaveEnvelope(t *testing.T) {
+	times := make([]time.Duration, 100)
+	points := make([]float64, 100)
+	for i := range times {
+		times[i] = time.Duration(i) * time.Millisecond
+		points[i] = float64(i % 10)
+	}
+	e, err := newWaveEnvelope(context.Background(), times, points, 0, 99*time.Millisecond, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.columns) != 10 {
+		t.Fatalf("expected 10 columns, got %d", len(e.columns))
+	}
+	if spc := e.samplesPerColumn(); spc != 10 {
+		t.Errorf("expected 10 samples per column, got %f", spc)
+	}
+	for i, c := range e.columns[:9] {
+		if c.min != 0 || c.max != 9 {
+			t.Errorf("expected column %d to range from 0 to 9, got %f to %f", i, c.min, c.max)
+		}
+	}
+}
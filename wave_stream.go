@@ -0,0 +1,114 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+//WaveStream is a ring buffer for samples of one or more channels that are pushed continuously, like from a sound card.
+//It keeps the latest samples up to its capacity and overwrites older ones. Push is safe while the samples are drawn
+//by a StreamingWaveDrawer from another goroutine
+type WaveStream struct {
+	mutex      sync.RWMutex
+	sampleRate int
+	capacity   int
+	channels   [][]float64
+	pushed     int64
+}
+
+//NewWaveStream is the constructor for WaveStream keeping the latest capacity samples of each channel
+func NewWaveStream(sampleRate int, capacity int, channels int) *WaveStream {
+	s := &WaveStream{
+		sampleRate: max(sampleRate, 1),
+		capacity:   max(capacity, 1),
+		channels:   make([][]float64, max(channels, 1)),
+	}
+	for i := range s.channels {
+		s.channels[i] = make([]float64, s.capacity)
+	}
+	return s
+}
+
+//Push appends samples to the end of the stream, one slice per channel. All channels need the same number of samples
+func (s *WaveStream) Push(samples ...[]float64) error {
+	if len(samples) != len(s.channels) {
+		return fmt.Errorf("%d channels pushed to a stream of %d: %w", len(samples), len(s.channels), ErrLengthMismatch)
+	}
+	for i, c := range samples {
+		if len(c) != len(samples[0]) {
+			return fmt.Errorf("channel %d has %d samples instead of %d: %w", i, len(c), len(samples[0]), ErrLengthMismatch)
+		}
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i, c := range samples {
+		//Only the latest samples fit if more than the capacity are pushed at once
+		skip := max(len(c)-s.capacity, 0)
+		for j, v := range c[skip:] {
+			s.channels[i][(s.pushed+int64(skip+j))%int64(s.capacity)] = v
+		}
+	}
+	s.pushed += int64(len(samples[0]))
+	return nil
+}
+
+//SampleRate returns the number of samples per second
+func (s *WaveStream) SampleRate() int {
+	return s.sampleRate
+}
+
+//Capacity returns the number of samples per channel kept in the stream
+func (s *WaveStream) Capacity() int {
+	return s.capacity
+}
+
+//Channels returns the number of channels
+func (s *WaveStream) Channels() int {
+	return len(s.channels)
+}
+
+//Pushed returns the number of samples per channel pushed since the stream was created, including overwritten ones
+func (s *WaveStream) Pushed() int64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.pushed
+}
+
+//Duration returns the time of all samples pushed since the stream was created
+func (s *WaveStream) Duration() time.Duration {
+	return time.Duration(float64(s.Pushed()) / float64(s.sampleRate) * float64(time.Second))
+}
+
+//envelope summarizes the samples of channel from index first up to last excluding, counted since the stream was
+//created. Samples that are overwritten already or not pushed yet are left out
+func (s *WaveStream) envelope(channel int, first int64, last int64) waveEnvelopeColumn {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	var c waveEnvelopeColumn
+	first = maxInt64(first, s.pushed-int64(s.capacity))
+	last = minInt64(last, s.pushed)
+	for i := maxInt64(first, 0); i < last; i++ {
+		v := s.channels[channel][i%int64(s.capacity)]
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		c.add(v)
+	}
+	return c
+}
+
+func maxInt64(one int64, two int64) int64 {
+	if one > two {
+		return one
+	}
+	return two
+}
+
+func minInt64(one int64, two int64) int64 {
+	if one < two {
+		return one
+	}
+	return two
+}
@@ -0,0 +1,109 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"fmt"
+	"time"
+)
+
+//margins are the spaces around a plot containing the title and the labels of the axes
+type margins struct {
+	top    int
+	right  int
+	bottom int
+	left   int
+}
+
+//union returns the larger of both margins on every side
+func (s margins) union(m margins) margins {
+	return margins{
+		top:    max(s.top, m.top),
+		right:  max(s.right, m.right),
+		bottom: max(s.bottom, m.bottom),
+		left:   max(s.left, m.left),
+	}
+}
+
+//widgetSize contains the size of the plot of a widget and its margins. Values that aren't set default to the
+//settings of the DrawerBuilder, so widgets can differ from the other plots in a figure
+type widgetSize struct {
+	width   int
+	height  int
+	margins *margins
+}
+
+//plotWidth returns the width of the plot without margins
+func (s *widgetSize) plotWidth(drawer *DrawerBuilder) int {
+	if s.width > 0 {
+		return s.width
+	}
+	return drawer.plotWidth
+}
+
+//plotHeight returns the height of the plot without margins
+func (s *widgetSize) plotHeight(drawer *DrawerBuilder) int {
+	if s.height > 0 {
+		return s.height
+	}
+	return drawer.plotHeight
+}
+
+//plotMargins returns the margins set with Margins or the margins fitting the text measured by the widget, which are
+//at least the label space of the DrawerBuilder on all sides
+func (s *widgetSize) plotMargins(drawer *DrawerBuilder, text margins) margins {
+	if s.margins != nil {
+		return *s.margins
+	}
+	return text.union(margins{
+		top:    drawer.labelSpace,
+		right:  drawer.labelSpace,
+		bottom: drawer.labelSpace,
+		left:   drawer.labelSpace,
+	})
+}
+
+//measure returns the size of the plot with the margins m. At least one pixel of the plot must be left
+func (s *widgetSize) measure(drawer *DrawerBuilder, m margins) Measurement {
+	return Measurement{
+		Width:     s.plotWidth(drawer) + m.left + m.right,
+		Height:    s.plotHeight(drawer) + m.top + m.bottom,
+		MinWidth:  m.left + m.right + 1,
+		MinHeight: m.top + m.bottom + 1,
+	}
+}
+
+//setMargins sets all margins, negative values are ignored
+func (s *widgetSize) setMargins(top int, right int, bottom int, left int) {
+	if top < 0 || right < 0 || bottom < 0 || left < 0 {
+		return
+	}
+	s.margins = &margins{
+		top:    top,
+		right:  right,
+		bottom: bottom,
+		left:   left,
+	}
+}
+
+//titleMargin returns the top margin fitting title drawn with style above the plot
+func (s *DrawerBuilder) titleMargin(title string, style TextStyle) int {
+	if title == "" {
+		return 0
+	}
+	return 2*s.spacePart + style.ascent() + style.descent()
+}
+
+//timeAxisMargins returns the bottom margin fitting the time labels below the plot and the left and right margins
+//fitting the labels of start and end centered at the edges of the plot
+func (s *DrawerBuilder) timeAxisMargins(start time.Duration, end time.Duration) margins {
+	style := s.tickStyle()
+	return margins{
+		bottom: 4*s.spacePart + s.spacePart/2 + style.ascent() + style.descent(),
+		left:   style.width(timeLabel(start))/2 + s.spacePart,
+		right:  style.width(timeLabel(end))/2 + s.spacePart,
+	}
+}
+
+//timeLabel returns the label of a time on the time axis
+func timeLabel(t time.Duration) string {
+	return fmt.Sprintf("%dms", t.Milliseconds())
+}
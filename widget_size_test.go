@@ -0,0 +1,92 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"image"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWidgetSize(t *testing.T) {
+	times := []time.Duration{0, time.Millisecond, 2 * time.Millisecond}
+	d := NewDrawer().PlotWidth(400).PlotHeight(200).LabelSpace(40)
+	overview := NewWaveDrawer(d, times, "overview").Height(30).Margins(10, 5, 20, 40)
+	spectrum := NewSpectrumDrawer(d, []float64{100, 200, 300}, "spectrum").Height(500)
+	d.AddPlot(overview).AddPlot(spectrum)
+
+	if size := overview.Measure(); size != (Measurement{Width: 445, Height: 60, MinWidth: 46, MinHeight: 31}) {
+		t.Errorf("expected overview of 445x60, got %+v", size)
+	}
+	//The bottom margin of the spectrum is widened to fit the notes and the frequency range
+	if size := spectrum.Measure(); size.Width != 480 || size.Height != 586 {
+		t.Errorf("expected spectrum of 480x586, got %+v", size)
+	}
+	want := []image.Rectangle{image.Rect(0, 0, 480, 60), image.Rect(0, 60, 480, 646)}
+	if l := d.layout(); !reflect.DeepEqual(l.bounds, want) {
+		t.Errorf("expected bounds %v, got %v", want, l.bounds)
+	}
+
+	//The overview is stretched to the width of the column, the margins stay the same
+	overview.cache = overview.newWaveDrawerCache(want[0])
+	if overview.cache.plotWidth != 435 || overview.cache.plotHeight != 30 {
+		t.Errorf("expected overview plot of 435x30, got %dx%d", overview.cache.plotWidth, overview.cache.plotHeight)
+	}
+	if x := overview.timeToX(0); x != 40 {
+		t.Errorf("expected start time at the left margin, got x=%d", x)
+	}
+
+	//Widgets without own settings follow the DrawerBuilder
+	d.PlotWidth(600)
+	if w := spectrum.Measure().Width; w != 680 {
+		t.Errorf("expected spectrum width of 680, got %d", w)
+	}
+}
+
+func TestWidgetTextMargins(t *testing.T) {
+	times := []time.Duration{0, time.Millisecond, 2 * time.Millisecond}
+	d := NewDrawer()
+	wave := NewWaveDrawer(d, times, "wave").SetItems(NewWaveDrawerItems([]float64{0, 0.5, -0.25}, red)).YUnit("mV")
+	spectrum := NewSpectrumDrawer(d, []float64{100, 200, 300}, "spectrum")
+	spectrogram := NewSpectrogramDrawer(d, times, []float64{100, 200}, [][]float64{{0, 1}, {1, 0}, {0, 0}}, "spectrogram")
+	colorbar := NewColorbarDrawer(d, -60, 0, "").YUnit("dBFS")
+	fixed := NewSpectrumDrawer(d, []float64{100, 200, 300}, "fixed").Margins(10, 10, 10, 10)
+
+	//The default sizes fit into the label space
+	if size := spectrum.Measure(); size.Width != 2160 || size.Height != 460 {
+		t.Errorf("expected spectrum of 2160x460, got %+v", size)
+	}
+	before := []Measurement{wave.Measure(), spectrum.Measure(), spectrogram.Measure(), colorbar.Measure(), fixed.Measure()}
+
+	d.FontSizes(60, 13, 12)
+	if wave.Measure().Height <= before[0].Height || spectrum.Measure().Height <= before[1].Height {
+		t.Errorf("expected the top margin to grow with the title size")
+	}
+	if wave.Measure().Width != before[0].Width {
+		t.Errorf("expected the title size to keep the width, got %d instead of %d", wave.Measure().Width, before[0].Width)
+	}
+
+	d.FontSizes(16, 13, 40)
+	tests := []struct {
+		name   string
+		got    Measurement
+		before Measurement
+		width  bool
+		height bool
+	}{
+		{name: "wave", got: wave.Measure(), before: before[0], width: true, height: true},
+		{name: "spectrum", got: spectrum.Measure(), before: before[1], height: true},
+		{name: "spectrogram", got: spectrogram.Measure(), before: before[2], width: true, height: true},
+		{name: "colorbar", got: colorbar.Measure(), before: before[3], width: true},
+	}
+	for _, test := range tests {
+		if grew := test.got.Width > test.before.Width; grew != test.width {
+			t.Errorf("%s: expected the width to grow with the tick size: %t, got %d instead of %d", test.name, test.width, test.got.Width, test.before.Width)
+		}
+		if grew := test.got.Height > test.before.Height; grew != test.height {
+			t.Errorf("%s: expected the height to grow with the tick size: %t, got %d instead of %d", test.name, test.height, test.got.Height, test.before.Height)
+		}
+	}
+	if fixed.Measure() != before[4] {
+		t.Errorf("expected margins set with Margins to stay, got %+v", fixed.Measure())
+	}
+}
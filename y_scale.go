@@ -0,0 +1,92 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"math"
+)
+
+//YScale defines how the values of the items are mapped to the y-axis of a plot
+type YScale int
+
+const (
+	//YScaleLinear scales every item linearly between its own minimum and maximum value. This is the default
+	YScaleLinear YScale = iota
+	//YScaleShared scales all items of a plot linearly between the common minimum and maximum of all items, so the
+	//items are comparable to each other
+	YScaleShared
+	//YScaleFixed scales all items linearly between a fixed minimum and maximum, so plots are comparable to each other
+	YScaleFixed
+	//YScaleDecibel shows the magnitudes in dBFS, where 1.0 is full scale (0dB), down to a configurable floor
+	YScaleDecibel
+)
+
+//defaultDecibelFloor is the lowest level shown with YScaleDecibel if not set otherwise
+const defaultDecibelFloor = -120.0
+
+//yRange maps values of items to the vertical pixels of a plot
+type yRange struct {
+	min     float64
+	max     float64
+	decibel bool
+}
+
+//newYRange creates a linear range between min and max. Empty ranges are widened so values can always be mapped
+func newYRange(min float64, max float64) yRange {
+	if math.IsNaN(min) || math.IsInf(min, 0) {
+		min = 0
+	}
+	if math.IsNaN(max) || math.IsInf(max, 0) || max <= min {
+		max = min + 1
+	}
+	return yRange{min: min, max: max}
+}
+
+//newDecibelRange creates a range in dBFS between floor and 0dB
+func newDecibelRange(floor float64) yRange {
+	if floor >= 0 {
+		floor = defaultDecibelFloor
+	}
+	return yRange{min: floor, max: 0, decibel: true}
+}
+
+//pointsRange creates a linear range between the minimum and maximum of all points. NaN and infinite points are ignored
+func pointsRange(points ...[]float64) yRange {
+	minValue := math.Inf(1)
+	maxValue := math.Inf(-1)
+	for _, p := range points {
+		for _, v := range p {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			maxValue = math.Max(maxValue, v)
+			minValue = math.Min(minValue, v)
+		}
+	}
+	return newYRange(minValue, maxValue)
+}
+
+//value converts a point to the unit of the range
+func (s yRange) value(v float64) float64 {
+	if s.decibel {
+		return 20 * math.Log10(math.Abs(v))
+	}
+	return v
+}
+
+//fraction returns the relative height of a value in the unit of the range, clamped to [0,1]
+func (s yRange) fraction(u float64) float64 {
+	f := (u - s.min) / (s.max - s.min)
+	if math.IsNaN(f) || f < 0 {
+		return 0
+	}
+	return math.Min(f, 1)
+}
+
+//toY recalculates a point to the y-coordinates of a plot with the given bottom and height
+func (s yRange) toY(v float64, bottom int, height int) int {
+	return s.unitToY(s.value(v), bottom, height)
+}
+
+//unitToY recalculates a value already in the unit of the range (like dB) to the y-coordinates of a plot
+func (s yRange) unitToY(u float64, bottom int, height int) int {
+	return bottom - int(s.fraction(u)*float64(height))
+}
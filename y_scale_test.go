@@ -0,0 +1,22 @@
+package go_hugipipes_signal_drawer
+
+import (
+	"testing"
+)
+
+func TestYRangeToY(t *testing.T) {
+	shared := pointsRange([]float64{0, 0.5}, []float64{-1, 1})
+	if y := shared.toY(0, 100, 100); y != 50 {
+		t.Errorf("expected 0 in the middle of the shared range, got y=%d", y)
+	}
+	db := newDecibelRange(-60)
+	cases := map[float64]int{1: 0, 0.001: 100, 0.0001: 100, 0: 100, -1: 0}
+	for v, want := range cases {
+		if y := db.toY(v, 100, 100); y != want {
+			t.Errorf("expected %f to be at y=%d in dBFS, got y=%d", v, want, y)
+		}
+	}
+	if y := newYRange(3, 3).toY(3, 100, 100); y != 100 {
+		t.Errorf("expected empty range to be widened, got y=%d", y)
+	}
+}